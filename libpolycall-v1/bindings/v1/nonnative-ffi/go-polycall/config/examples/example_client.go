@@ -6,12 +6,21 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"os"
 	"time"
 
 	polycall "../src"
+
+	"github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/internal/logging"
 )
 
+// log is this demo's own diagnostic logger (the fmt.Println calls below
+// stay as-is: they're the demonstration's narrated console output, not
+// log records). Separate from polycall.PolyCallClient's own internal
+// logger (set via polycall.WithLogger), since this program observes the
+// client from outside rather than being part of it.
+var log = logging.New(logging.Options{Name: "example-client"})
+
 // BookData represents book information for API demonstrations
 type BookData struct {
 	Title  string `json:"title"`
@@ -31,33 +40,34 @@ func main() {
 	fmt.Println("=" * 50)
 
 	// Initialize client with configuration
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize PolyCall client: %v", err)
+		log.Error("failed to initialize PolyCall client", "error", err)
+		os.Exit(1)
 	}
 
 	// Demonstrate connection lifecycle
 	fmt.Println("\n📡 Phase 1: Connection Management")
 	if err := demonstrateConnection(client); err != nil {
-		log.Printf("⚠️  Connection demonstration failed: %v", err)
+		log.Warn("connection demonstration failed", "error", err)
 	}
 
 	// Demonstrate HTTP API calls
 	fmt.Println("\n📋 Phase 2: HTTP API Interaction")
 	if err := demonstrateHTTPAPI(client); err != nil {
-		log.Printf("⚠️  HTTP API demonstration failed: %v", err)
+		log.Warn("HTTP API demonstration failed", "error", err)
 	}
 
 	// Demonstrate state management
 	fmt.Println("\n🔄 Phase 3: State Management")
 	if err := demonstrateStateManagement(client); err != nil {
-		log.Printf("⚠️  State management demonstration failed: %v", err)
+		log.Warn("state management demonstration failed", "error", err)
 	}
 
 	// Cleanup
 	fmt.Println("\n🧹 Phase 4: Cleanup and Disconnection")
 	if err := client.Disconnect(); err != nil {
-		log.Printf("⚠️  Disconnection failed: %v", err)
+		log.Warn("disconnection failed", "error", err)
 	} else {
 		fmt.Println("✅ Successfully disconnected from LibPolyCall server")
 	}
@@ -67,7 +77,7 @@ func main() {
 
 func demonstrateConnection(client *polycall.PolyCallClient) error {
 	fmt.Println("  🔌 Attempting connection to LibPolyCall server...")
-	
+
 	// Connection may fail if server isn't running - this is expected
 	if err := client.Connect(); err != nil {
 		fmt.Printf("  ⚠️  Connection failed (server may not be running): %v\n", err)
@@ -77,7 +87,7 @@ func demonstrateConnection(client *polycall.PolyCallClient) error {
 
 	fmt.Printf("  ✅ Connected: %t\n", client.IsConnected())
 	fmt.Printf("  ✅ Authenticated: %t\n", client.IsAuthenticated())
-	
+
 	return nil
 }
 
@@ -95,7 +105,7 @@ func demonstrateHTTPAPI(client *polycall.PolyCallClient) error {
 		fmt.Println("  💡 Ensure LibPolyCall server is running and accessible")
 	} else {
 		fmt.Printf("  ✅ Book created successfully: %s\n", string(createResp))
-		
+
 		// Parse response to show structured data
 		var createdBook BookData
 		if json.Unmarshal(createResp, &createdBook) == nil {
@@ -110,7 +120,7 @@ func demonstrateHTTPAPI(client *polycall.PolyCallClient) error {
 		fmt.Printf("  ⚠️  Books retrieval failed: %v\n", err)
 	} else {
 		fmt.Printf("  ✅ Books retrieved successfully: %s\n", string(booksResp))
-		
+
 		// Parse and display books
 		var books []BookData
 		if json.Unmarshal(booksResp, &books) == nil {
@@ -133,7 +143,7 @@ func demonstrateStateManagement(client *polycall.PolyCallClient) error {
 		fmt.Println("  💡 State management requires LibPolyCall server with state machine enabled")
 	} else {
 		fmt.Printf("  ✅ States retrieved successfully: %s\n", string(statesResp))
-		
+
 		// Parse and display states
 		var states []StateData
 		if json.Unmarshal(statesResp, &states) == nil {
@@ -151,7 +161,7 @@ func demonstrateStateManagement(client *polycall.PolyCallClient) error {
 	// Demonstrate state transition
 	fmt.Println("  🔄 Attempting state transition...")
 	transitionData := map[string]interface{}{
-		"reason": "Go client demonstration",
+		"reason":    "Go client demonstration",
 		"timestamp": time.Now().Unix(),
 	}
 
@@ -174,8 +184,8 @@ func demonstrateAdvancedFeatures(client *polycall.PolyCallClient) error {
 	if client.IsAuthenticated() {
 		fmt.Println("    🎯 Executing custom command...")
 		cmdData := map[string]interface{}{
-			"action": "status_check",
-			"client": "go-polycall",
+			"action":  "status_check",
+			"client":  "go-polycall",
 			"version": "1.0.0",
 		}
 
@@ -15,7 +15,7 @@
 // - Language agnostic protocol communication
 //
 // Usage:
-//   client, err := polycall.NewPolyCallClient()
+//   client, err := polycall.NewPolyCallClient("")
 //   if err != nil {
 //       log.Fatal(err)
 //   }
@@ -94,8 +94,8 @@ const (
 //   if err != nil {
 //       return fmt.Errorf("client initialization failed: %w", err)
 //   }
-func NewClient(configPath ...string) (*Client, error) {
-	return NewPolyCallClient(configPath...)
+func NewClient(configPath string, opts ...ClientOption) (*Client, error) {
+	return NewPolyCallClient(configPath, opts...)
 }
 
 // Version returns the Go binding version information
@@ -115,7 +115,7 @@ func BuildInfo() map[string]string {
 		"collaborator":      "Nnamdi Michael Okpala",
 		"project":           "Aegis LibPolyCall",
 		"binding_type":      "thin_client",
-		"communication":     "http_tcp_hybrid",
+		"communication":     "http_tcp_grpc",
 		"security":          "zero_trust",
 	}
 }
@@ -0,0 +1,204 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// AuthMethodBearer, AuthMethodHMAC, and AuthMethodClientCert are the values
+// Configuration.Auth.Method accepts; an empty Method defaults to
+// AuthMethodBearer.
+const (
+	AuthMethodBearer     = "bearer"
+	AuthMethodHMAC       = "hmac"
+	AuthMethodClientCert = "client_cert"
+)
+
+// authResponse is what a MessageAuth reply is expected to carry back.
+type authResponse struct {
+	Authenticated bool   `json:"authenticated"`
+	Error         string `json:"error"`
+	SessionKey    string `json:"session_key"`
+}
+
+// authMethod returns the configured auth method, defaulting to
+// AuthMethodBearer the same way buildAuthPayload does.
+func (c *PolyCallClient) authMethod() string {
+	if c.config.Auth.Method == "" {
+		return AuthMethodBearer
+	}
+	return c.config.Auth.Method
+}
+
+// authenticate runs the MessageAuth exchange handshake starts after the
+// magic bytes: it sends a method-specific credential (buildAuthPayload),
+// waits for the correlated response processMessage delivers through
+// pendingReqs, and only returns success once the server actually confirms -
+// unlike the magic-byte exchange itself, which has no real server in this
+// binding to negotiate with yet.
+func (c *PolyCallClient) authenticate() error {
+	payload, err := c.buildAuthPayload()
+	if err != nil {
+		return fmt.Errorf("failed to build %s auth payload: %w", c.authMethod(), err)
+	}
+
+	raw, err := c.sendMessageAndWait(c.ctx, MessageAuth, payload, FlagReliable)
+	if err != nil {
+		return fmt.Errorf("auth exchange failed: %w", err)
+	}
+
+	var resp authResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return fmt.Errorf("failed to decode auth response: %w", err)
+	}
+	if !resp.Authenticated {
+		if resp.Error == "" {
+			resp.Error = "server rejected credentials"
+		}
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	if resp.SessionKey != "" {
+		key, err := hex.DecodeString(resp.SessionKey)
+		if err != nil {
+			return fmt.Errorf("server returned malformed session key: %w", err)
+		}
+		c.mutex.Lock()
+		c.sessionKey = key
+		c.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// buildAuthPayload encodes the credential the configured AuthMethod calls
+// for, to be carried in a MessageAuth frame.
+func (c *PolyCallClient) buildAuthPayload() ([]byte, error) {
+	switch c.authMethod() {
+	case AuthMethodBearer:
+		return json.Marshal(map[string]string{
+			"method": AuthMethodBearer,
+			"token":  c.config.Auth.Token,
+		})
+
+	case AuthMethodHMAC:
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("failed to generate challenge nonce: %w", err)
+		}
+		mac := hmac.New(sha256.New, []byte(c.config.Auth.HMACSecret))
+		mac.Write(nonce)
+		return json.Marshal(map[string]string{
+			"method":    AuthMethodHMAC,
+			"nonce":     hex.EncodeToString(nonce),
+			"signature": hex.EncodeToString(mac.Sum(nil)),
+		})
+
+	case AuthMethodClientCert:
+		fingerprint, err := clientCertFingerprint(c.config.TLS.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fingerprint client cert: %w", err)
+		}
+		return json.Marshal(map[string]string{
+			"method":      AuthMethodClientCert,
+			"fingerprint": fingerprint,
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown auth method %q", c.authMethod())
+	}
+}
+
+// clientCertFingerprint returns the hex-encoded SHA-256 fingerprint of the
+// first certificate in certFile's PEM bundle, matching how TLS client
+// certificates are commonly identified out of band.
+func clientCertFingerprint(certFile string) (string, error) {
+	if certFile == "" {
+		return "", fmt.Errorf("auth.method=%s requires tls.cert_file to be set", AuthMethodClientCert)
+	}
+
+	pemBytes, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cert_file: %w", err)
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return "", fmt.Errorf("no PEM block found in %s", certFile)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// encryptPayload seals payload under the session key authenticate negotiated,
+// for frames sent with FlagEncrypted set. The returned bytes are
+// nonce||ciphertext, matching decryptPayload's expectations.
+func (c *PolyCallClient) encryptPayload(payload []byte) ([]byte, error) {
+	gcm, err := c.sessionGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, payload, nil), nil
+}
+
+// decryptPayload opens a nonce||ciphertext payload encryptPayload produced,
+// for frames received with FlagEncrypted set.
+func (c *PolyCallClient) decryptPayload(payload []byte) ([]byte, error) {
+	gcm, err := c.sessionGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted payload shorter than nonce size %d", gcm.NonceSize())
+	}
+	nonce, ciphertext := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// sessionGCM builds an AES-GCM AEAD over the session key authenticate
+// negotiated during handshake.
+func (c *PolyCallClient) sessionGCM() (cipher.AEAD, error) {
+	c.mutex.RLock()
+	key := c.sessionKey
+	c.mutex.RUnlock()
+
+	if len(key) == 0 {
+		return nil, fmt.Errorf("flag_encrypted set but no session key negotiated yet")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
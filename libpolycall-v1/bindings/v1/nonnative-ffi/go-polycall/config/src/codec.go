@@ -0,0 +1,117 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec marshals/unmarshals SendCommand/SendHTTPRequest payloads to/from
+// wire bytes, decoupling the JSON format LibPolyCall has always spoken
+// from the protobuf format handshake can negotiate instead.
+type Codec interface {
+	// Marshal encodes v to wire bytes.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into v.
+	Unmarshal(data []byte, v interface{}) error
+	// ContentType is the HTTP Content-Type SendHTTPRequest sets for
+	// payloads this codec marshaled.
+	ContentType() string
+	// Name identifies this codec in handshake negotiation ("json", "protobuf").
+	Name() string
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) ContentType() string                        { return "application/json" }
+func (jsonCodec) Name() string                               { return "json" }
+
+// protobufCodec marshals through proto.Marshal/Unmarshal, so v must
+// implement proto.Message - RegisterMessage lets callers resolve a
+// SendCommand command name to the concrete generated type to use.
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+func (protobufCodec) Name() string        { return "protobuf" }
+
+// JSONCodec and ProtobufCodec are the built-in Codecs; exported so
+// WithCodec can select one directly instead of relying on handshake
+// negotiation.
+var (
+	JSONCodec     Codec = jsonCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+// supportedCodecNames lists every codec this binding advertises during
+// handshake, in preference order.
+func supportedCodecNames() []string {
+	return []string{JSONCodec.Name(), ProtobufCodec.Name()}
+}
+
+// codecByName resolves a name handshake negotiation picked (or a server
+// response advertised) back to the Codec it names.
+func codecByName(name string) (Codec, bool) {
+	switch name {
+	case JSONCodec.Name():
+		return JSONCodec, true
+	case ProtobufCodec.Name():
+		return ProtobufCodec, true
+	default:
+		return nil, false
+	}
+}
+
+// messageRegistry maps a SendCommand command name to the proto.Message
+// type RegisterMessage associated with it, so a client negotiated onto
+// the protobuf codec knows which concrete type to marshal/unmarshal
+// instead of JSON's schemaless map[string]interface{}.
+var messageRegistry = struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}{types: make(map[string]reflect.Type)}
+
+// RegisterMessage associates name (a SendCommand command) with msg's
+// concrete proto.Message type, so users can plug in their own generated
+// types instead of this binding hard-coding a message catalog.
+func RegisterMessage(name string, msg proto.Message) {
+	messageRegistry.mu.Lock()
+	defer messageRegistry.mu.Unlock()
+	messageRegistry.types[name] = reflect.TypeOf(msg).Elem()
+}
+
+// newRegisteredMessage returns a fresh zero-value instance of whatever
+// proto.Message RegisterMessage associated with name, or nil if none was
+// registered.
+func newRegisteredMessage(name string) proto.Message {
+	messageRegistry.mu.RLock()
+	t, ok := messageRegistry.types[name]
+	messageRegistry.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return reflect.New(t).Interface().(proto.Message)
+}
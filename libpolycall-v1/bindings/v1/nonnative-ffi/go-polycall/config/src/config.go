@@ -0,0 +1,307 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Configuration represents the Go binding configuration. Top-level fields
+// (no section header in the .polycallrc file) are populated directly;
+// TLS and Auth are populated from their own [tls]/[auth] sections. Adding a
+// field here - top-level or nested - is enough for parseINI to pick it up
+// via its ini tag; no parser code needs to change.
+type Configuration struct {
+	Port               string `ini:"port"`
+	ServerType         string `ini:"server_type"`
+	Workspace          string `ini:"workspace"`
+	LogLevel           string `ini:"log_level"`
+	MaxConnections     int    `ini:"max_connections"`
+	SupportsFormatting bool   `ini:"supports_formatting"`
+	MaxMemory          string `ini:"max_memory"`
+	Timeout            int    `ini:"timeout"`
+	AllowRemote        bool   `ini:"allow_remote"`
+	RequireAuth        bool   `ini:"require_auth"`
+	StrictPortBinding  bool   `ini:"strict_port_binding"`
+	GoVersion          string `ini:"go_version"`
+	Transport          string `ini:"transport"`
+
+	TLS  TLSConfig  `ini:"tls"`
+	Auth AuthConfig `ini:"auth"`
+}
+
+// TLSConfig is populated from a [tls] section and controls the TLS dialer
+// wrapping net.DialTimeout for the tcp/grpc transports.
+type TLSConfig struct {
+	CAFile             string `ini:"ca_file"`
+	CertFile           string `ini:"cert_file"`
+	KeyFile            string `ini:"key_file"`
+	ServerName         string `ini:"server_name"`
+	InsecureSkipVerify bool   `ini:"insecure_skip_verify"`
+}
+
+// AuthConfig is populated from an [auth] section and selects how handshake
+// proves the client's identity to the server.
+type AuthConfig struct {
+	Method     string `ini:"method"`
+	Token      string `ini:"token"`
+	HMACSecret string `ini:"hmac_secret"`
+}
+
+// defaultConfiguration returns the same baseline values loadConfiguration
+// has always shipped, before any file is merged on top.
+func defaultConfiguration() *Configuration {
+	return &Configuration{
+		Port:               "3003:8083",
+		ServerType:         "go",
+		Workspace:          "/opt/polycall/services/go",
+		LogLevel:           "info",
+		MaxConnections:     100,
+		SupportsFormatting: true,
+		MaxMemory:          "1G",
+		Timeout:            30,
+		AllowRemote:        false,
+		RequireAuth:        true,
+		StrictPortBinding:  true,
+		GoVersion:          "1.21",
+		Transport:          "tcp",
+	}
+}
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateEnv replaces every ${VAR} in s with os.Getenv(VAR), leaving
+// the reference untouched if the variable isn't set.
+func interpolateEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if val, ok := os.LookupEnv(name); ok {
+			return val
+		}
+		return ref
+	})
+}
+
+// unquote strips a single layer of matching "..." or '...' quoting.
+func unquote(s string) string {
+	if len(s) >= 2 {
+		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
+
+// iniFieldIndex maps an ini tag to the field index of a struct type, for a
+// struct whose fields are scalars (string/int/bool) rather than nested
+// sections.
+func iniFieldIndex(t reflect.Type) map[string]int {
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("ini"); tag != "" {
+			index[tag] = i
+		}
+	}
+	return index
+}
+
+// setScalarField assigns value (already unquoted and env-interpolated) to
+// target's field at i, converting to the field's underlying type. Unparsable
+// ints/bools are left at their current (default) value, matching the old
+// switch statement's silent-skip behavior.
+func setScalarField(target reflect.Value, i int, value string) {
+	field := target.Field(i)
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		if n, err := strconv.Atoi(value); err == nil {
+			field.SetInt(int64(n))
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			field.SetBool(b)
+		}
+	}
+}
+
+// parseINI scans r as an .polycallrc-style file - "key = value" pairs,
+// optionally grouped under "[section]" headers - and applies each pair onto
+// config via reflection: unsectioned keys set config's own ini-tagged
+// fields, and keys under "[section]" set the ini-tagged fields of whichever
+// nested struct field of config has a matching ini tag (e.g. "[tls]" targets
+// Configuration.TLS). Unknown sections and keys are ignored, same as the
+// switch statement this replaces.
+func parseINI(r io.Reader, config *Configuration) error {
+	root := reflect.ValueOf(config).Elem()
+	rootIndex := iniFieldIndex(root.Type())
+
+	sections := make(map[string]reflect.Value)
+	for tag, i := range rootIndex {
+		if root.Field(i).Kind() == reflect.Struct {
+			sections[tag] = root.Field(i)
+		}
+	}
+
+	target := root
+	targetIndex := rootIndex
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if section, ok := sections[name]; ok {
+				target = section
+				targetIndex = iniFieldIndex(target.Type())
+			} else {
+				// Unknown section: fields with no home are ignored until it
+				// closes, rather than silently landing on the wrong struct.
+				target = reflect.Value{}
+				targetIndex = nil
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || !target.IsValid() {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := interpolateEnv(unquote(strings.TrimSpace(parts[1])))
+
+		if i, ok := targetIndex[key]; ok {
+			setScalarField(target, i, value)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// LoadConfigurationFrom parses an .polycallrc-style document from r on top
+// of the built-in defaults, without touching the filesystem.
+func LoadConfigurationFrom(r io.Reader) (*Configuration, error) {
+	config := defaultConfiguration()
+	if err := parseINI(r, config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	return config, nil
+}
+
+// MergeConfigurationFiles loads the built-in defaults, then parses each path
+// in order on top of the running result, so later files override earlier
+// ones field-by-field (a key a later file doesn't mention keeps whatever an
+// earlier file, or the default, set). This lets operators layer a site-wide
+// config with a per-user override on top.
+func MergeConfigurationFiles(paths ...string) (*Configuration, error) {
+	config := defaultConfiguration()
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open config file %s: %w", path, err)
+		}
+		err = parseINI(file, config)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	return config, nil
+}
+
+// loadConfiguration loads the single configPath this binding has always
+// accepted, defaulting to config/go.polycallrc when empty.
+func loadConfiguration(configPath string) (*Configuration, error) {
+	if configPath == "" {
+		configPath = filepath.Join("config", "go.polycallrc")
+	}
+	return MergeConfigurationFiles(configPath)
+}
+
+// byteSizeSuffixes maps a MaxMemory suffix to its power-of-two multiplier.
+var byteSizeSuffixes = map[byte]int64{
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+}
+
+// parseByteSize parses a byte-size string like "1G" or "512M", or a bare
+// byte count, returning the size in bytes.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty byte size")
+	}
+
+	last := s[len(s)-1]
+	if mult, ok := byteSizeSuffixes[strings.ToUpper(string(last))[0]]; ok {
+		n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte size %q: %w", s, err)
+		}
+		return n * mult, nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q: %w", s, err)
+	}
+	return n, nil
+}
+
+// Validate checks Configuration for internally-inconsistent values,
+// returning every problem found (via errors.Join) rather than just the
+// first, so a misconfigured operator sees the whole list in one pass.
+func (c *Configuration) Validate() error {
+	var errs []error
+
+	parts := strings.Split(c.Port, ":")
+	if len(parts) != 2 {
+		errs = append(errs, fmt.Errorf("port: expected \"host:container\", got %q", c.Port))
+	} else {
+		for _, label := range []struct {
+			name string
+			s    string
+		}{{"host port", parts[0]}, {"container port", parts[1]}} {
+			n, err := strconv.Atoi(label.s)
+			if err != nil || n < 1 || n > 65535 {
+				errs = append(errs, fmt.Errorf("port: %s %q out of range 1-65535", label.name, label.s))
+			}
+		}
+	}
+
+	if _, err := parseByteSize(c.MaxMemory); err != nil {
+		errs = append(errs, fmt.Errorf("max_memory: %w", err))
+	}
+
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("timeout: must be > 0, got %d", c.Timeout))
+	}
+
+	if c.StrictPortBinding {
+		if c.Workspace == "" {
+			errs = append(errs, fmt.Errorf("workspace: required when strict_port_binding is set"))
+		} else if _, err := os.Stat(c.Workspace); err != nil {
+			errs = append(errs, fmt.Errorf("workspace: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,376 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// filterNode is one node of the AST a filter expression parses into, in the
+// spirit of the filter language Consul accepts on v1/catalog/services:
+// boolean and/or/not combining comparisons on JSON fields.
+type filterNode interface {
+	Eval(obj map[string]interface{}) bool
+}
+
+type andNode struct{ left, right filterNode }
+
+func (n andNode) Eval(obj map[string]interface{}) bool {
+	return n.left.Eval(obj) && n.right.Eval(obj)
+}
+
+type orNode struct{ left, right filterNode }
+
+func (n orNode) Eval(obj map[string]interface{}) bool {
+	return n.left.Eval(obj) || n.right.Eval(obj)
+}
+
+type notNode struct{ node filterNode }
+
+func (n notNode) Eval(obj map[string]interface{}) bool {
+	return !n.node.Eval(obj)
+}
+
+// cmpOp identifies which comparison a cmpNode performs.
+type cmpOp int
+
+const (
+	cmpEqual cmpOp = iota
+	cmpNotEqual
+	cmpMatches
+	cmpIn
+	cmpNotIn
+)
+
+type cmpNode struct {
+	field  string
+	op     cmpOp
+	value  string
+	values []string
+	regex  *regexp.Regexp
+}
+
+func (n cmpNode) Eval(obj map[string]interface{}) bool {
+	actual := fmt.Sprintf("%v", obj[n.field])
+	switch n.op {
+	case cmpEqual:
+		return actual == n.value
+	case cmpNotEqual:
+		return actual != n.value
+	case cmpMatches:
+		return n.regex.MatchString(actual)
+	case cmpIn:
+		return containsString(n.values, actual)
+	case cmpNotIn:
+		return !containsString(n.values, actual)
+	default:
+		return false
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFilter compiles a filter expression (e.g.
+// `Author == "Okpala" and Title matches "^Aegis"`) into an AST that can be
+// evaluated against decoded JSON objects with Eval.
+func ParseFilter(expr string) (filterNode, error) {
+	p := &filterParser{tokens: tokenizeFilter(expr)}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in filter expression", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+// EvaluateFilter parses expr and evaluates it against obj in one step. An
+// empty expr always matches.
+func EvaluateFilter(expr string, obj map[string]interface{}) (bool, error) {
+	if expr == "" {
+		return true, nil
+	}
+	node, err := ParseFilter(expr)
+	if err != nil {
+		return false, err
+	}
+	return node.Eval(obj), nil
+}
+
+// filterJSONArray re-evaluates expr over a JSON array response, for peers
+// that silently ignore the ?filter= query parameter. Non-array responses
+// (a single object, or a server that already honored the filter and
+// returned a differently-shaped body) are passed through unchanged.
+func filterJSONArray(body []byte, expr string) ([]byte, error) {
+	var items []map[string]interface{}
+	if err := json.Unmarshal(body, &items); err != nil {
+		// Not a JSON array of objects - nothing we can filter client-side,
+		// so trust the server's response as-is.
+		return body, nil
+	}
+
+	matched := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		ok, err := EvaluateFilter(expr, item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate filter: %w", err)
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+
+	return json.Marshal(matched)
+}
+
+// filterParser is a recursive-descent parser over tokenizeFilter's output.
+// Grammar (lowest to highest precedence):
+//
+//	or-expr  := and-expr ("or" and-expr)*
+//	and-expr := unary ("and" unary)*
+//	unary    := "not" unary | "(" or-expr ")" | comparison
+//	comparison := IDENT ("==" | "!=" | "matches" | ["not"] "in") operand
+type filterParser struct {
+	tokens  []string
+	pos     int
+	lastErr error
+}
+
+func (p *filterParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if strings.EqualFold(p.peek(), "not") {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node: node}, nil
+	}
+
+	if p.peek() == "(" {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("expected ) in filter expression, got %q", p.peek())
+		}
+		p.next()
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	field := p.next()
+	if field == "" || isFilterKeyword(field) {
+		return nil, fmt.Errorf("expected field name in filter expression, got %q", field)
+	}
+
+	op := p.next()
+	switch strings.ToLower(op) {
+	case "==":
+		value := p.nextOperand()
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		return cmpNode{field: field, op: cmpEqual, value: value}, nil
+	case "!=":
+		value := p.nextOperand()
+		if err := p.err(); err != nil {
+			return nil, err
+		}
+		return cmpNode{field: field, op: cmpNotEqual, value: value}, nil
+	case "matches":
+		pattern := p.nextOperand()
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matches pattern %q: %w", pattern, err)
+		}
+		return cmpNode{field: field, op: cmpMatches, regex: re}, nil
+	case "in":
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return cmpNode{field: field, op: cmpIn, values: values}, nil
+	case "not":
+		if strings.ToLower(p.next()) != "in" {
+			return nil, fmt.Errorf("expected 'in' after 'not' in filter expression")
+		}
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return cmpNode{field: field, op: cmpNotIn, values: values}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %q in filter expression", op)
+	}
+}
+
+// nextOperand consumes and unquotes a string-literal operand; parse errors
+// are reported lazily via err() to keep parseComparison's case arms short.
+func (p *filterParser) nextOperand() string {
+	value, err := unquoteFilterLiteral(p.next())
+	if err != nil {
+		p.lastErr = err
+	}
+	return value
+}
+
+func (p *filterParser) err() error {
+	err := p.lastErr
+	p.lastErr = nil
+	return err
+}
+
+func (p *filterParser) parseList() ([]string, error) {
+	if p.next() != "(" {
+		return nil, fmt.Errorf("expected ( to start an in-list in filter expression")
+	}
+	var values []string
+	for {
+		tok := p.next()
+		if tok == ")" {
+			break
+		}
+		if tok == "," {
+			continue
+		}
+		value, err := unquoteFilterLiteral(tok)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func isFilterKeyword(tok string) bool {
+	switch strings.ToLower(tok) {
+	case "and", "or", "not", "in", "matches", "(", ")":
+		return true
+	default:
+		return false
+	}
+}
+
+func unquoteFilterLiteral(tok string) (string, error) {
+	if len(tok) >= 2 && tok[0] == '"' && tok[len(tok)-1] == '"' {
+		return strconv.Unquote(tok)
+	}
+	return "", fmt.Errorf("expected a quoted string literal, got %q", tok)
+}
+
+// tokenizeFilter splits a filter expression into identifiers, quoted string
+// literals, operators, and punctuation.
+func tokenizeFilter(expr string) []string {
+	var tokens []string
+	runes := []rune(expr)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				j++
+			}
+			if j < len(runes) {
+				j++ // include closing quote
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case r == '=' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, "==")
+			i += 2
+		case r == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, "!=")
+			i += 2
+		default:
+			j := i
+			for j < len(runes) && !isFilterDelimiter(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens
+}
+
+func isFilterDelimiter(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '(', ')', ',', '"':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,241 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// ReconnectPolicy controls the exponential backoff a KeepAliveWatcher uses
+// once a heartbeat timeout is detected.
+type ReconnectPolicy struct {
+	// MaxRetries caps how many reconnect attempts are made before the
+	// watcher gives up and emits StateReconnectFailed.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps how large the doubling backoff can grow.
+	MaxBackoff time.Duration
+}
+
+// DefaultReconnectPolicy is used when NewPolyCallClient's caller doesn't
+// supply one via WithReconnect.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		MaxRetries:     5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// DefaultKeepAliveInterval and DefaultKeepAliveTimeout are used if
+// WithKeepAlive is given a non-positive interval/timeout.
+const (
+	DefaultKeepAliveInterval = 15 * time.Second
+	DefaultKeepAliveTimeout  = 45 * time.Second
+)
+
+// ConnectionState is the state a ConnectionEvent reports.
+type ConnectionState int
+
+const (
+	// StateDisconnected is emitted when a heartbeat timeout is first
+	// detected, before any reconnect attempt is made.
+	StateDisconnected ConnectionState = iota
+	// StateReconnecting is emitted once per reconnect attempt.
+	StateReconnecting
+	// StateConnected is emitted once a reconnect attempt succeeds.
+	StateConnected
+	// StateReconnectFailed is emitted when ReconnectPolicy.MaxRetries is
+	// exhausted; the KeepAliveWatcher stops after this.
+	StateReconnectFailed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnectFailed:
+		return "reconnect_failed"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectionEvent is emitted on KeepAliveWatcher.Events as the connection
+// transitions between states.
+type ConnectionEvent struct {
+	State ConnectionState
+	Err   error
+}
+
+// KeepAliveWatcher sends MessageHeartbeat frames on an idle connection and
+// tracks server liveness via processMessage.recordHeartbeat. When the peer
+// stops responding within Timeout, it drives a capped, exponential-backoff
+// reconnect loop via PolyCallClient.reattempt, modeled on
+// TokenLifetimeWatcher's own background-goroutine shape.
+type KeepAliveWatcher struct {
+	client   *PolyCallClient
+	interval time.Duration
+	timeout  time.Duration
+	policy   ReconnectPolicy
+	events   chan ConnectionEvent
+
+	mu       sync.Mutex
+	lastSeen time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewKeepAliveWatcher constructs a watcher for client; a non-positive
+// interval/timeout falls back to DefaultKeepAliveInterval/Timeout.
+func NewKeepAliveWatcher(client *PolyCallClient, interval, timeout time.Duration, policy ReconnectPolicy) *KeepAliveWatcher {
+	if interval <= 0 {
+		interval = DefaultKeepAliveInterval
+	}
+	if timeout <= 0 {
+		timeout = DefaultKeepAliveTimeout
+	}
+	return &KeepAliveWatcher{
+		client:   client,
+		interval: interval,
+		timeout:  timeout,
+		policy:   policy,
+		events:   make(chan ConnectionEvent, 8),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start spawns the heartbeat goroutine and returns immediately. Stop
+// cancels it; the client's context also stops it when the client
+// disconnects.
+func (w *KeepAliveWatcher) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.markSeen()
+	go w.run(ctx)
+}
+
+// Stop cancels the heartbeat loop and waits for it to exit.
+func (w *KeepAliveWatcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	<-w.done
+}
+
+// Events surfaces connection-state transitions as they happen, so callers
+// can react to a disconnect/reconnect cycle without polling IsConnected.
+func (w *KeepAliveWatcher) Events() <-chan ConnectionEvent {
+	return w.events
+}
+
+// markSeen records that a heartbeat (or a fresh reconnect) was just
+// observed, resetting the idle clock readMessages checks against.
+func (w *KeepAliveWatcher) markSeen() {
+	w.mu.Lock()
+	w.lastSeen = time.Now()
+	w.mu.Unlock()
+}
+
+// emit delivers event to a consumer reading Events, dropping it rather than
+// blocking the heartbeat loop if nobody is listening.
+func (w *KeepAliveWatcher) emit(event ConnectionEvent) {
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+func (w *KeepAliveWatcher) run(ctx context.Context) {
+	defer close(w.done)
+
+	log := w.client.logger.With("phase", "connect", "subsystem", "keepalive")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := w.client.sendFrame(MessageHeartbeat, nil, FlagNone); err != nil {
+			log.Warn("heartbeat send failed", "error", err)
+		}
+
+		w.mu.Lock()
+		idle := time.Since(w.lastSeen)
+		w.mu.Unlock()
+		if idle <= w.timeout {
+			continue
+		}
+
+		log.Warn("peer heartbeat timed out, reconnecting", "idle", idle)
+		w.emit(ConnectionEvent{State: StateDisconnected, Err: fmt.Errorf("heartbeat timeout after %s", idle)})
+
+		if w.reconnect(ctx, log) {
+			w.markSeen()
+			w.emit(ConnectionEvent{State: StateConnected})
+			continue
+		}
+
+		w.emit(ConnectionEvent{State: StateReconnectFailed})
+		return
+	}
+}
+
+// reconnect retries client.reattempt up to policy.MaxRetries times with
+// jittered exponential backoff, returning whether a retry succeeded.
+func (w *KeepAliveWatcher) reconnect(ctx context.Context, log hclog.Logger) bool {
+	backoff := w.policy.InitialBackoff
+
+	for attempt := 1; attempt <= w.policy.MaxRetries; attempt++ {
+		w.emit(ConnectionEvent{State: StateReconnecting, Err: fmt.Errorf("attempt %d/%d", attempt, w.policy.MaxRetries)})
+
+		if err := w.client.reattempt(); err == nil {
+			return true
+		} else {
+			log.Warn("reconnect attempt failed", "attempt", attempt, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(jitteredBackoff(backoff, w.policy.MaxBackoff)):
+		}
+
+		backoff *= 2
+		if backoff > w.policy.MaxBackoff {
+			backoff = w.policy.MaxBackoff
+		}
+	}
+	return false
+}
+
+// jitteredBackoff returns base (capped at max) plus up to 50% jitter.
+func jitteredBackoff(base, max time.Duration) time.Duration {
+	if base > max {
+		base = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+	d := base + jitter
+	if d > max {
+		return max
+	}
+	return d
+}
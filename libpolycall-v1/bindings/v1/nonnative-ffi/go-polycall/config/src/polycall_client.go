@@ -4,7 +4,6 @@
 package polycall
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"crypto/sha256"
@@ -12,14 +11,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/protobuf/proto"
 )
 
 // Protocol constants matching LibPolyCall specification
@@ -55,22 +55,6 @@ const (
 	FlagReliable   ProtocolFlag = 0x08
 )
 
-// Configuration represents the Go binding configuration
-type Configuration struct {
-	Port               string `ini:"port"`
-	ServerType         string `ini:"server_type"`
-	Workspace          string `ini:"workspace"`
-	LogLevel           string `ini:"log_level"`
-	MaxConnections     int    `ini:"max_connections"`
-	SupportsFormatting bool   `ini:"supports_formatting"`
-	MaxMemory          string `ini:"max_memory"`
-	Timeout            int    `ini:"timeout"`
-	AllowRemote        bool   `ini:"allow_remote"`
-	RequireAuth        bool   `ini:"require_auth"`
-	StrictPortBinding  bool   `ini:"strict_port_binding"`
-	GoVersion          string `ini:"go_version"`
-}
-
 // PolyCallClient represents the main LibPolyCall Go client
 type PolyCallClient struct {
 	config       *Configuration
@@ -79,13 +63,29 @@ type PolyCallClient struct {
 	containerPort int
 	connected    bool
 	authenticated bool
-	conn         net.Conn
+	transport    Transport
 	httpClient   *http.Client
 	sequence     uint32
 	mutex        sync.RWMutex
 	pendingReqs  map[uint32]chan []byte
 	ctx          context.Context
 	cancel       context.CancelFunc
+	logger       hclog.Logger
+	authFailed   bool
+	watcher      *TokenLifetimeWatcher
+	codec        Codec
+	sessionKey   []byte
+
+	keepAliveInterval time.Duration
+	keepAliveTimeout  time.Duration
+	reconnectPolicy   ReconnectPolicy
+	keepAlive         *KeepAliveWatcher
+
+	renewBehavior RenewBehavior
+
+	httpPool               *connPool
+	tcpPool                *connPool
+	portBindingRejections  int64
 }
 
 // ProtocolHeader represents the LibPolyCall protocol header
@@ -98,9 +98,92 @@ type ProtocolHeader struct {
 	Checksum      uint32
 }
 
+// ClientOption configures a PolyCallClient at construction time.
+type ClientOption func(*PolyCallClient) error
+
+// WithLogger installs an hclog.Logger on the client. Every state transition
+// in Connect/ExecuteFeature-equivalents (handshake, auth, HTTP, command) logs
+// through it with component=polycall and a phase field. If no logger is
+// supplied, NewPolyCallClient installs a default one sized by the
+// configuration's log_level, defaulting to JSON output when log_level=debug.
+func WithLogger(logger hclog.Logger) ClientOption {
+	return func(c *PolyCallClient) error {
+		if logger == nil {
+			return fmt.Errorf("logger cannot be nil")
+		}
+		c.logger = logger.With("component", "polycall")
+		return nil
+	}
+}
+
+// WithCodec selects the Codec SendCommand/SendHTTPRequest marshal payloads
+// with, overriding JSON's default. Use ProtobufCodec (with RegisterMessage
+// for each command's generated type) to speak protobuf without waiting on
+// handshake to negotiate it.
+func WithCodec(codec Codec) ClientOption {
+	return func(c *PolyCallClient) error {
+		if codec == nil {
+			return fmt.Errorf("codec cannot be nil")
+		}
+		c.codec = codec
+		return nil
+	}
+}
+
+// WithKeepAlive enables heartbeat-based liveness checking: a MessageHeartbeat
+// frame is sent every interval, and if timeout passes with no heartbeat or
+// other traffic from the server, Connect's background watcher drives a
+// reconnect via the configured ReconnectPolicy (WithReconnect). A
+// non-positive interval/timeout falls back to DefaultKeepAliveInterval/
+// DefaultKeepAliveTimeout. Without this option, no heartbeat is sent and a
+// dropped connection is only noticed when the next request fails.
+func WithKeepAlive(interval, timeout time.Duration) ClientOption {
+	return func(c *PolyCallClient) error {
+		c.keepAliveInterval = interval
+		c.keepAliveTimeout = timeout
+		return nil
+	}
+}
+
+// WithReconnect overrides the backoff policy KeepAliveWatcher uses once a
+// heartbeat timeout is detected. Has no effect unless WithKeepAlive is also
+// given a positive interval.
+func WithReconnect(policy ReconnectPolicy) ClientOption {
+	return func(c *PolyCallClient) error {
+		c.reconnectPolicy = policy
+		return nil
+	}
+}
+
+// WithRenewBehavior selects how handshake's TokenLifetimeWatcher reacts to
+// a renewal failure: RenewBehaviorIgnoreErrors (the default) keeps retrying
+// on schedule, while RenewBehaviorFatal marks the client unauthenticated
+// (IsAuthenticated returns false) on the first renewal error.
+func WithRenewBehavior(behavior RenewBehavior) ClientOption {
+	return func(c *PolyCallClient) error {
+		c.renewBehavior = behavior
+		return nil
+	}
+}
+
+// defaultLogger builds the client's logger from configuration when the
+// caller didn't supply one via WithLogger.
+func defaultLogger(config *Configuration) hclog.Logger {
+	level := hclog.LevelFromString(config.LogLevel)
+	if level == hclog.NoLevel {
+		level = hclog.Info
+	}
+
+	return hclog.New(&hclog.LoggerOptions{
+		Name:       "polycall",
+		Level:      level,
+		JSONFormat: config.LogLevel == "debug",
+	}).With("component", "polycall")
+}
+
 // NewPolyCallClient creates a new Go PolyCall client instance
-func NewPolyCallClient(configPath ...string) (*PolyCallClient, error) {
-	config, err := loadConfiguration(configPath...)
+func NewPolyCallClient(configPath string, opts ...ClientOption) (*PolyCallClient, error) {
+	config, err := loadConfiguration(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -123,17 +206,42 @@ func NewPolyCallClient(configPath ...string) (*PolyCallClient, error) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	httpClient := &http.Client{
+		Timeout: time.Duration(config.Timeout) * time.Second,
+	}
+
+	transport, err := newTransport(config, "localhost", containerPort, httpClient)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to build transport: %w", err)
+	}
+
 	client := &PolyCallClient{
 		config:        config,
 		host:          "localhost",
 		port:          hostPort,
 		containerPort: containerPort,
-		httpClient: &http.Client{
-			Timeout: time.Duration(config.Timeout) * time.Second,
-		},
-		pendingReqs: make(map[uint32]chan []byte),
-		ctx:         ctx,
-		cancel:      cancel,
+		transport:     transport,
+		httpClient:    httpClient,
+		pendingReqs:   make(map[uint32]chan []byte),
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        defaultLogger(config),
+		codec:         JSONCodec,
+		reconnectPolicy: DefaultReconnectPolicy(),
+		httpPool:      newConnPool("http", config.MaxConnections),
+		tcpPool:       newConnPool("tcp", config.MaxConnections),
+	}
+
+	if config.StrictPortBinding {
+		httpClient.CheckRedirect = client.portBindingCheckRedirect
+	}
+
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			cancel()
+			return nil, fmt.Errorf("client configuration failed: %w", err)
+		}
 	}
 
 	return client, nil
@@ -142,20 +250,24 @@ func NewPolyCallClient(configPath ...string) (*PolyCallClient, error) {
 // Connect establishes connection to LibPolyCall server
 func (c *PolyCallClient) Connect() error {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
 	if c.connected {
+		c.mutex.Unlock()
+		c.logger.With("phase", "connect").Warn("connect called while already connected")
 		return fmt.Errorf("already connected")
 	}
+	c.mutex.Unlock()
 
-	// Attempt TCP connection first
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", c.host, c.containerPort), DefaultTimeout)
-	if err != nil {
+	start := time.Now()
+	log := c.logger.With("phase", "connect", "remote_addr", fmt.Sprintf("%s:%d", c.host, c.containerPort))
+
+	if err := c.transport.Handshake(c.ctx); err != nil {
+		log.Error("connect failed", "error", err, "elapsed", time.Since(start), "transport", c.config.Transport)
 		return fmt.Errorf("failed to connect to LibPolyCall server: %w", err)
 	}
 
-	c.conn = conn
+	c.mutex.Lock()
 	c.connected = true
+	c.mutex.Unlock()
 
 	// Start message handling goroutine
 	go c.handleMessages()
@@ -163,45 +275,188 @@ func (c *PolyCallClient) Connect() error {
 	// Perform handshake
 	if err := c.handshake(); err != nil {
 		c.Disconnect()
+		log.Error("handshake failed", "error", err, "elapsed", time.Since(start))
 		return fmt.Errorf("handshake failed: %w", err)
 	}
 
+	if c.keepAliveInterval > 0 {
+		watcher := NewKeepAliveWatcher(c, c.keepAliveInterval, c.keepAliveTimeout, c.reconnectPolicy)
+		c.mutex.Lock()
+		c.keepAlive = watcher
+		c.mutex.Unlock()
+		watcher.Start(c.ctx)
+	}
+
+	log.Info("connected", "elapsed", time.Since(start))
 	return nil
 }
 
 // Disconnect closes the connection to LibPolyCall server
 func (c *PolyCallClient) Disconnect() error {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
 	if !c.connected {
+		c.mutex.Unlock()
 		return nil
 	}
 
+	watcher := c.watcher
+	c.watcher = nil
+	keepAlive := c.keepAlive
+	c.keepAlive = nil
+
+	c.connected = false
+	c.authenticated = false
+	c.authFailed = false
+
+	// Unblock every sendOnceAndWait call still waiting on a response that
+	// will now never arrive; closing (rather than sending on) each channel
+	// lets its receiver distinguish "connection gone" from a real response.
+	for sequence, ch := range c.pendingReqs {
+		close(ch)
+		delete(c.pendingReqs, sequence)
+	}
+	c.mutex.Unlock()
+
 	c.cancel()
-	
-	if c.conn != nil {
-		c.conn.Close()
-		c.conn = nil
+
+	// watcher/keepAlive.Stop() block waiting for their goroutines to exit,
+	// and those goroutines may be mid-sendFrame (which locks c.mutex), so
+	// both are stopped with c.mutex released to avoid deadlocking against
+	// them.
+	if watcher != nil {
+		watcher.Stop()
 	}
+	if keepAlive != nil {
+		keepAlive.Stop()
+	}
+
+	c.transport.Close()
+
+	return nil
+}
+
+// Shutdown cancels the token lifetime watcher and disconnects cleanly. It
+// honors ctx's deadline while waiting for the watcher to stop.
+func (c *PolyCallClient) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Disconnect()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RenewalErrors surfaces token renewal failures from the background
+// TokenLifetimeWatcher. The channel is nil until the client has
+// authenticated at least once.
+func (c *PolyCallClient) RenewalErrors() <-chan error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.watcher == nil {
+		return nil
+	}
+	return c.watcher.Errors()
+}
 
+// KeepAliveEvents surfaces connection-state transitions (disconnect,
+// reconnect attempts, reconnect success/failure) from the background
+// KeepAliveWatcher. The channel is nil until the client has connected with
+// WithKeepAlive configured.
+func (c *PolyCallClient) KeepAliveEvents() <-chan ConnectionEvent {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.keepAlive == nil {
+		return nil
+	}
+	return c.keepAlive.Events()
+}
+
+// markAuthFailed records a non-recoverable token renewal error so
+// IsAuthenticated reflects it immediately.
+func (c *PolyCallClient) markAuthFailed() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.authFailed = true
+}
+
+// recordHeartbeat notes that a MessageHeartbeat (or any other traffic
+// processMessage just saw) arrived, resetting the idle clock the
+// KeepAliveWatcher's reconnect decision is based on. A no-op if no
+// KeepAliveWatcher is running.
+func (c *PolyCallClient) recordHeartbeat() {
+	c.mutex.RLock()
+	keepAlive := c.keepAlive
+	c.mutex.RUnlock()
+	if keepAlive != nil {
+		keepAlive.markSeen()
+	}
+}
+
+// reattempt redials the transport and replays the handshake in place,
+// without touching c.ctx/c.cancel the way Disconnect does - those belong to
+// the KeepAliveWatcher's own reconnect loop for the life of the client, and
+// context.WithCancel's cancellation can't be undone. Only the token
+// watcher, pendingReqs, and connection state are reset; the KeepAliveWatcher
+// itself keeps running and is left alone by its own reconnect loop.
+func (c *PolyCallClient) reattempt() error {
+	c.mutex.Lock()
+	if c.watcher != nil {
+		c.watcher.Stop()
+		c.watcher = nil
+	}
 	c.connected = false
 	c.authenticated = false
+	for sequence, ch := range c.pendingReqs {
+		close(ch)
+		delete(c.pendingReqs, sequence)
+	}
+	c.mutex.Unlock()
+
+	c.transport.Close()
+
+	if err := c.transport.Handshake(c.ctx); err != nil {
+		return fmt.Errorf("failed to reconnect to LibPolyCall server: %w", err)
+	}
+
+	c.mutex.Lock()
+	c.connected = true
+	c.mutex.Unlock()
+
+	go c.handleMessages()
+
+	if err := c.handshake(); err != nil {
+		return fmt.Errorf("handshake failed: %w", err)
+	}
 
 	return nil
 }
 
-// SendHTTPRequest sends HTTP request to LibPolyCall server
+// SendHTTPRequest sends HTTP request to LibPolyCall server. It queues
+// behind max_connections other in-flight HTTP requests rather than
+// rejecting the caller outright; see connPool.
 func (c *PolyCallClient) SendHTTPRequest(method, path string, data interface{}) ([]byte, error) {
+	release, err := c.httpPool.Acquire(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	url := fmt.Sprintf("http://%s:%d%s", c.host, c.containerPort, path)
-	
+
+	codec := c.Codec()
+
 	var reqBody io.Reader
 	if data != nil {
-		jsonData, err := json.Marshal(data)
+		encoded, err := codec.Marshal(data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request data: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonData)
+		reqBody = bytes.NewReader(encoded)
 	}
 
 	req, err := http.NewRequestWithContext(c.ctx, method, url, reqBody)
@@ -210,7 +465,7 @@ func (c *PolyCallClient) SendHTTPRequest(method, path string, data interface{})
 	}
 
 	if data != nil {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", codec.ContentType())
 	}
 
 	resp, err := c.httpClient.Do(req)
@@ -226,28 +481,68 @@ func (c *PolyCallClient) SendHTTPRequest(method, path string, data interface{})
 	return io.ReadAll(resp.Body)
 }
 
-// SendCommand sends a command to LibPolyCall server
+// SendCommand sends a command to LibPolyCall server, encoded with the
+// client's negotiated Codec. Under JSONCodec (the default) command and data
+// are wrapped in {"command":..., "data":...}, matching LibPolyCall's
+// long-standing wire shape. Under ProtobufCodec, data must itself implement
+// proto.Message - there's no JSON-style wrapper to carry both command and
+// payload in one protobuf message, so RegisterMessage(command, ...) is how
+// the server is expected to know which type command names.
 func (c *PolyCallClient) SendCommand(command string, data interface{}) ([]byte, error) {
-	if !c.authenticated {
+	if c.config.RequireAuth && !c.IsAuthenticated() {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
-	payload := map[string]interface{}{
-		"command": command,
-		"data":    data,
-	}
+	codec := c.Codec()
 
-	payloadBytes, err := json.Marshal(payload)
+	var payloadBytes []byte
+	var err error
+	if codec.Name() == ProtobufCodec.Name() {
+		msg, ok := data.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("protobuf codec negotiated for command %q: data (%T) must implement proto.Message", command, data)
+		}
+		payloadBytes, err = codec.Marshal(msg)
+	} else {
+		payloadBytes, err = codec.Marshal(map[string]interface{}{
+			"command": command,
+			"data":    data,
+		})
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal command payload: %w", err)
 	}
 
-	return c.sendMessage(MessageCommand, payloadBytes, FlagReliable)
+	return c.sendMessageAndWait(c.ctx, MessageCommand, payloadBytes, FlagReliable)
 }
 
-// GetStates retrieves all states from LibPolyCall server
-func (c *PolyCallClient) GetStates() ([]byte, error) {
-	return c.SendHTTPRequest("GET", "/states", nil)
+// ListWithFilter retrieves resource (e.g. "/books", "/states") from
+// LibPolyCall, forwarding expr as a ?filter= query parameter so a server
+// that understands the filter language (see ParseFilter) can do the work.
+// If expr is non-empty, the response is also re-evaluated client-side
+// against the same expression, so bindings degrade gracefully against
+// older servers that silently ignore ?filter=.
+func (c *PolyCallClient) ListWithFilter(resource, expr string) ([]byte, error) {
+	path := resource
+	if expr != "" {
+		path = fmt.Sprintf("%s?filter=%s", resource, url.QueryEscape(expr))
+	}
+
+	body, err := c.SendHTTPRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if expr == "" {
+		return body, nil
+	}
+
+	return filterJSONArray(body, expr)
+}
+
+// GetStates retrieves all states from LibPolyCall server, optionally
+// narrowed by a single filter expression (see ListWithFilter).
+func (c *PolyCallClient) GetStates(filter ...string) ([]byte, error) {
+	return c.ListWithFilter("/states", firstFilter(filter))
 }
 
 // GetState retrieves a specific state from LibPolyCall server
@@ -271,9 +566,19 @@ func (c *PolyCallClient) CreateBook(title, author string) ([]byte, error) {
 	return c.SendHTTPRequest("POST", "/books", bookData)
 }
 
-// GetBooks retrieves all books (example API call)
-func (c *PolyCallClient) GetBooks() ([]byte, error) {
-	return c.SendHTTPRequest("GET", "/books", nil)
+// GetBooks retrieves all books (example API call), optionally narrowed by a
+// single filter expression (see ListWithFilter).
+func (c *PolyCallClient) GetBooks(filter ...string) ([]byte, error) {
+	return c.ListWithFilter("/books", firstFilter(filter))
+}
+
+// firstFilter returns the first element of a variadic filter argument, or
+// "" if none was given.
+func firstFilter(filter []string) string {
+	if len(filter) == 0 {
+		return ""
+	}
+	return filter[0]
 }
 
 // IsConnected returns whether the client is connected
@@ -283,58 +588,208 @@ func (c *PolyCallClient) IsConnected() bool {
 	return c.connected
 }
 
-// IsAuthenticated returns whether the client is authenticated
+// IsAuthenticated returns whether the client is authenticated. It flips to
+// false the moment the token lifetime watcher observes a non-recoverable
+// renewal error, even if the underlying connection is still open.
 func (c *PolyCallClient) IsAuthenticated() bool {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
-	return c.authenticated
+	return c.authenticated && !c.authFailed
 }
 
 // Private methods
 
 func (c *PolyCallClient) handshake() error {
+	log := c.logger.With("phase", "auth")
+
 	magicBytes := make([]byte, 4)
 	binary.LittleEndian.PutUint32(magicBytes, ProtocolMagic)
-	
-	_, err := c.sendMessage(MessageHandshake, magicBytes, FlagReliable)
+
+	// Advertise every codec this binding can speak after the magic bytes,
+	// so a server that understands codec negotiation can pick one instead
+	// of assuming JSON.
+	advertisement, err := json.Marshal(supportedCodecNames())
 	if err != nil {
+		return fmt.Errorf("failed to encode codec advertisement: %w", err)
+	}
+	handshakePayload := append(magicBytes, advertisement...)
+
+	err = c.sendFrame(MessageHandshake, handshakePayload, FlagReliable)
+	if err != nil {
+		log.Error("handshake message failed", "error", err)
 		return fmt.Errorf("handshake message failed: %w", err)
 	}
 
-	// Wait for handshake response
-	// In a full implementation, this would wait for the response
+	// In a full implementation, the handshake response would also let us
+	// adopt whichever codec name the server picked via codecByName; until
+	// then the codec WithCodec selected (JSONCodec by default) stands
+	// unchanged.
+
+	if err := c.authenticate(); err != nil {
+		log.Error("authentication failed", "error", err, "method", c.authMethod())
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
 	c.authenticated = true
+	c.authFailed = false
+
+	c.watcher = NewTokenLifetimeWatcher(c, DefaultTokenTTL, nil, c.renewBehavior)
+	c.watcher.Start(c.ctx)
+
+	log.Info("authenticated", "codec", c.Codec().Name(), "auth_method", c.authMethod())
 	return nil
 }
 
-func (c *PolyCallClient) sendMessage(msgType MessageType, payload []byte, flags ProtocolFlag) ([]byte, error) {
-	if !c.connected || c.conn == nil {
-		return nil, fmt.Errorf("not connected")
+// Codec returns the Codec this client currently marshals/unmarshals
+// payloads with: whatever WithCodec selected, or JSONCodec if neither
+// WithCodec nor handshake negotiation (see handshake's codec advertisement)
+// has picked a different one.
+func (c *PolyCallClient) Codec() Codec {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.codec == nil {
+		return JSONCodec
 	}
+	return c.codec
+}
+
+// DecodeResponse decodes data with the client's negotiated codec, falling
+// back to JSONCodec if that fails - a server doesn't always honor a
+// protobuf negotiation for every response, so a caller that gets garbage
+// back from the primary codec gets one more chance before erroring out.
+func (c *PolyCallClient) DecodeResponse(data []byte, v interface{}) error {
+	codec := c.Codec()
+	if err := codec.Unmarshal(data, v); err != nil {
+		if codec.Name() == JSONCodec.Name() {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if jsonErr := JSONCodec.Unmarshal(data, v); jsonErr != nil {
+			return fmt.Errorf("negotiated codec %s failed to decode (%v), json fallback also failed: %w", codec.Name(), err, jsonErr)
+		}
+	}
+	return nil
+}
 
+// sendFrame sends msgType/payload/flags as a single frame under a freshly
+// allocated sequence number, without waiting for any correlated response.
+// It's for handshake/auth exchanges, whose replies arrive as their own
+// message types (MessageHandshake, MessageAuth) that processMessage
+// handles directly rather than routing through pendingReqs.
+func (c *PolyCallClient) sendFrame(msgType MessageType, payload []byte, flags ProtocolFlag) error {
+	if !c.connected || c.transport == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	release, err := c.tcpPool.Acquire(c.ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if flags&FlagEncrypted != 0 {
+		payload, err = c.encryptPayload(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt payload: %w", err)
+		}
+	}
+
+	c.mutex.Lock()
 	c.sequence++
-	header := ProtocolHeader{
-		Version:       ProtocolVersion,
-		Type:          msgType,
-		Flags:         flags,
-		Sequence:      c.sequence,
-		PayloadLength: uint32(len(payload)),
-		Checksum:      calculateChecksum(payload),
+	sequence := c.sequence
+	c.mutex.Unlock()
+
+	c.logger.Debug("sending frame", "phase", "command", "msg_type", msgType, "flags", flags, "request_id", sequence)
+
+	return c.transport.Send(msgType, flags, sequence, payload)
+}
+
+// sendMessageAndWait sends payload as msgType and blocks for the
+// MessageResponse frame processMessage delivers back under the same
+// sequence number, honoring ctx cancellation and the configured per-request
+// timeout. FlagReliable messages are retried up to DefaultRetryCount times
+// if the deadline elapses before a response arrives.
+func (c *PolyCallClient) sendMessageAndWait(ctx context.Context, msgType MessageType, payload []byte, flags ProtocolFlag) ([]byte, error) {
+	timeout := time.Duration(c.config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultTimeout
 	}
 
-	// Create message
-	var buf bytes.Buffer
-	if err := binary.Write(&buf, binary.LittleEndian, header); err != nil {
-		return nil, fmt.Errorf("failed to write header: %w", err)
+	attempts := 1
+	if flags&FlagReliable != 0 {
+		attempts = DefaultRetryCount
 	}
-	buf.Write(payload)
 
-	// Send message
-	if _, err := c.conn.Write(buf.Bytes()); err != nil {
-		return nil, fmt.Errorf("failed to send message: %w", err)
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		resp, err := c.sendOnceAndWait(ctx, msgType, payload, flags, timeout)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, lastErr)
+}
 
-	return payload, nil
+// sendOnceAndWait is the single-attempt body sendMessageAndWait retries:
+// allocate a sequence under mutex, register its response channel in
+// pendingReqs, write the frame, then select on the channel, ctx.Done(), and
+// a per-attempt deadline.
+func (c *PolyCallClient) sendOnceAndWait(ctx context.Context, msgType MessageType, payload []byte, flags ProtocolFlag, timeout time.Duration) ([]byte, error) {
+	release, err := c.tcpPool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	c.mutex.Lock()
+	if !c.connected || c.transport == nil {
+		c.mutex.Unlock()
+		return nil, fmt.Errorf("not connected")
+	}
+	c.sequence++
+	sequence := c.sequence
+	respCh := make(chan []byte, 1)
+	c.pendingReqs[sequence] = respCh
+	c.mutex.Unlock()
+
+	if flags&FlagEncrypted != 0 {
+		encrypted, err := c.encryptPayload(payload)
+		if err != nil {
+			c.mutex.Lock()
+			delete(c.pendingReqs, sequence)
+			c.mutex.Unlock()
+			return nil, fmt.Errorf("failed to encrypt payload: %w", err)
+		}
+		payload = encrypted
+	}
+
+	c.logger.Debug("sending message", "phase", "command", "msg_type", msgType, "flags", flags, "request_id", sequence)
+
+	if err := c.transport.Send(msgType, flags, sequence, payload); err != nil {
+		c.mutex.Lock()
+		delete(c.pendingReqs, sequence)
+		c.mutex.Unlock()
+		return nil, err
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case resp, ok := <-respCh:
+		if !ok {
+			return nil, fmt.Errorf("connection closed while awaiting response to request %d", sequence)
+		}
+		return resp, nil
+	case <-attemptCtx.Done():
+		c.mutex.Lock()
+		delete(c.pendingReqs, sequence)
+		c.mutex.Unlock()
+		return nil, attemptCtx.Err()
+	}
 }
 
 func (c *PolyCallClient) handleMessages() {
@@ -357,64 +812,82 @@ func (c *PolyCallClient) handleMessages() {
 }
 
 func (c *PolyCallClient) readMessage() error {
-	if c.conn == nil {
+	if c.transport == nil {
 		return fmt.Errorf("connection is nil")
 	}
 
-	// Set read deadline
-	c.conn.SetReadDeadline(time.Now().Add(DefaultTimeout))
-
-	// Read header
-	headerBytes := make([]byte, HeaderSize)
-	if _, err := io.ReadFull(c.conn, headerBytes); err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+	header, payload, err := c.transport.Recv(c.ctx)
+	if err != nil {
+		return err
 	}
 
-	// Parse header
+	// Process message based on type
+	return c.processMessage(header, payload)
+}
+
+// DecodeFrame parses a single LibPolyCall protocol frame (header + payload)
+// out of data received from an untrusted peer. It never trusts the
+// attacker-controlled PayloadLength far enough to allocate beyond
+// MaxPayloadSize, and it never panics on truncated or malformed input -
+// every failure mode returns a typed error instead.
+func DecodeFrame(data []byte) (ProtocolHeader, []byte, error) {
 	var header ProtocolHeader
-	if err := binary.Read(bytes.NewReader(headerBytes), binary.LittleEndian, &header); err != nil {
-		return fmt.Errorf("failed to parse header: %w", err)
+
+	if len(data) < HeaderSize {
+		return header, nil, fmt.Errorf("frame too short: %d bytes, need at least %d", len(data), HeaderSize)
+	}
+
+	if err := binary.Read(bytes.NewReader(data[:HeaderSize]), binary.LittleEndian, &header); err != nil {
+		return header, nil, fmt.Errorf("failed to parse header: %w", err)
 	}
 
-	// Validate header
 	if header.Version != ProtocolVersion {
-		return fmt.Errorf("protocol version mismatch: expected %d, got %d", ProtocolVersion, header.Version)
+		return header, nil, fmt.Errorf("protocol version mismatch: expected %d, got %d", ProtocolVersion, header.Version)
 	}
 
 	if header.PayloadLength > MaxPayloadSize {
-		return fmt.Errorf("payload size exceeds maximum: %d > %d", header.PayloadLength, MaxPayloadSize)
+		return header, nil, fmt.Errorf("payload size exceeds maximum: %d > %d", header.PayloadLength, MaxPayloadSize)
 	}
 
-	// Read payload
-	payload := make([]byte, header.PayloadLength)
-	if header.PayloadLength > 0 {
-		if _, err := io.ReadFull(c.conn, payload); err != nil {
-			return fmt.Errorf("failed to read payload: %w", err)
-		}
+	remaining := data[HeaderSize:]
+	if uint32(len(remaining)) < header.PayloadLength {
+		return header, nil, fmt.Errorf("truncated payload: expected %d bytes, got %d", header.PayloadLength, len(remaining))
 	}
 
-	// Verify checksum
-	if calculateChecksum(payload) != header.Checksum {
-		return fmt.Errorf("checksum verification failed")
+	payload := remaining[:header.PayloadLength]
+
+	if CalculateChecksum(payload) != header.Checksum {
+		return header, nil, fmt.Errorf("checksum verification failed")
 	}
 
-	// Process message based on type
-	return c.processMessage(header, payload)
+	return header, payload, nil
 }
 
 func (c *PolyCallClient) processMessage(header ProtocolHeader, payload []byte) error {
+	if header.Flags&FlagEncrypted != 0 {
+		decrypted, err := c.decryptPayload(payload)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %v payload: %w", header.Type, err)
+		}
+		payload = decrypted
+	}
+
 	switch header.Type {
 	case MessageHandshake:
 		// Handle handshake response
 		return nil
-	case MessageAuth:
-		// Handle authentication response
-		c.authenticated = true
-		return nil
-	case MessageResponse:
-		// Handle command response
-		if ch, exists := c.pendingReqs[header.Sequence]; exists {
+	case MessageAuth, MessageResponse:
+		// Deliver the response to whichever sendMessageAndWait call (auth
+		// exchange or command) registered this sequence number, if any is
+		// still waiting; unsolicited frames (e.g. a renewal notification
+		// sent via sendFrame, which registers no waiter) are dropped.
+		c.mutex.Lock()
+		ch, exists := c.pendingReqs[header.Sequence]
+		if exists {
 			delete(c.pendingReqs, header.Sequence)
+		}
+		c.mutex.Unlock()
+		if exists {
 			ch <- payload
 			close(ch)
 		}
@@ -423,95 +896,15 @@ func (c *PolyCallClient) processMessage(header ProtocolHeader, payload []byte) e
 		// Handle error response
 		return fmt.Errorf("server error: %s", string(payload))
 	case MessageHeartbeat:
-		// Handle heartbeat
+		c.recordHeartbeat()
 		return nil
 	default:
 		return fmt.Errorf("unknown message type: %d", header.Type)
 	}
 }
 
-func calculateChecksum(data []byte) uint32 {
+func CalculateChecksum(data []byte) uint32 {
 	hash := sha256.Sum256(data)
 	return binary.LittleEndian.Uint32(hash[:4])
 }
 
-func loadConfiguration(configPaths ...string) (*Configuration, error) {
-	var configPath string
-	
-	if len(configPaths) > 0 && configPaths[0] != "" {
-		configPath = configPaths[0]
-	} else {
-		// Default configuration path
-		configPath = filepath.Join("config", "go.polycallrc")
-	}
-
-	file, err := os.Open(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
-	}
-	defer file.Close()
-
-	config := &Configuration{
-		Port:               "3003:8083",
-		ServerType:         "go",
-		Workspace:          "/opt/polycall/services/go",
-		LogLevel:           "info",
-		MaxConnections:     100,
-		SupportsFormatting: true,
-		MaxMemory:          "1G",
-		Timeout:            30,
-		AllowRemote:        false,
-		RequireAuth:        true,
-		StrictPortBinding:  true,
-		GoVersion:          "1.21",
-	}
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "port":
-			config.Port = value
-		case "server_type":
-			config.ServerType = value
-		case "workspace":
-			config.Workspace = value
-		case "log_level":
-			config.LogLevel = value
-		case "max_connections":
-			if val, err := strconv.Atoi(value); err == nil {
-				config.MaxConnections = val
-			}
-		case "supports_formatting":
-			config.SupportsFormatting = value == "true"
-		case "max_memory":
-			config.MaxMemory = value
-		case "timeout":
-			if val, err := strconv.Atoi(value); err == nil {
-				config.Timeout = val
-			}
-		case "allow_remote":
-			config.AllowRemote = value == "true"
-		case "require_auth":
-			config.RequireAuth = value == "true"
-		case "strict_port_binding":
-			config.StrictPortBinding = value == "true"
-		case "go_version":
-			config.GoVersion = value
-		}
-	}
-
-	return config, scanner.Err()
-}
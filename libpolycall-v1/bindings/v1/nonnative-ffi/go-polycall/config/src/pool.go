@@ -0,0 +1,117 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPoolExhausted is returned by a connPool when max_connections are all
+// in use and the caller's context is done before a slot frees up, so
+// callers can back off instead of retrying in a tight loop.
+type ErrPoolExhausted struct {
+	Pool       string
+	RetryAfter time.Duration
+}
+
+func (e *ErrPoolExhausted) Error() string {
+	return fmt.Sprintf("%s pool exhausted, retry after %s", e.Pool, e.RetryAfter)
+}
+
+// connPool caps in-flight work at a configured max_connections and reports
+// the Prometheus-style counters client.Metrics() snapshots.
+type connPool struct {
+	name      string
+	sem       chan struct{}
+	inFlight  int64
+	waitNanos int64
+}
+
+func newConnPool(name string, max int) *connPool {
+	if max <= 0 {
+		max = 1
+	}
+	return &connPool{name: name, sem: make(chan struct{}, max)}
+}
+
+// Acquire blocks until a slot is free or ctx is done, whichever comes
+// first, so a client with max_connections=N queues its (N+1)th concurrent
+// request rather than failing it outright. It only returns
+// ErrPoolExhausted once ctx ends the wait.
+func (p *connPool) Acquire(ctx context.Context) (release func(), err error) {
+	start := time.Now()
+
+	select {
+	case p.sem <- struct{}{}:
+	default:
+		select {
+		case p.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, &ErrPoolExhausted{Pool: p.name, RetryAfter: DefaultTimeout}
+		}
+	}
+
+	atomic.AddInt64(&p.waitNanos, int64(time.Since(start)))
+	atomic.AddInt64(&p.inFlight, 1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&p.inFlight, -1)
+			<-p.sem
+		})
+	}, nil
+}
+
+// InFlight returns the number of requests currently holding a slot.
+func (p *connPool) InFlight() int64 {
+	return atomic.LoadInt64(&p.inFlight)
+}
+
+// WaitSeconds returns the cumulative time every Acquire call has spent
+// waiting for a slot, matching the polycall_pool_wait_seconds counter.
+func (p *connPool) WaitSeconds() float64 {
+	return time.Duration(atomic.LoadInt64(&p.waitNanos)).Seconds()
+}
+
+// Metrics is a point-in-time snapshot of the counters Prometheus would
+// scrape as polycall_requests_in_flight, polycall_pool_wait_seconds, and
+// polycall_port_binding_rejections_total.
+type Metrics struct {
+	RequestsInFlight           int64
+	PoolWaitSeconds            float64
+	PortBindingRejectionsTotal int64
+}
+
+// Metrics returns a snapshot of the client's connection-pool counters.
+func (c *PolyCallClient) Metrics() Metrics {
+	return Metrics{
+		RequestsInFlight:           c.httpPool.InFlight() + c.tcpPool.InFlight(),
+		PoolWaitSeconds:            c.httpPool.WaitSeconds() + c.tcpPool.WaitSeconds(),
+		PortBindingRejectionsTotal: atomic.LoadInt64(&c.portBindingRejections),
+	}
+}
+
+// portBindingCheckRedirect enforces strict_port_binding against HTTP
+// redirects: a LibPolyCall peer (or anything impersonating one) must not be
+// able to redirect the client off the host:port negotiated from the
+// .polycallrc port mapping. Every rejection increments
+// polycall_port_binding_rejections_total.
+func (c *PolyCallClient) portBindingCheckRedirect(req *http.Request, via []*http.Request) error {
+	if !c.portBindingAllowed(req.URL) {
+		atomic.AddInt64(&c.portBindingRejections, 1)
+		return fmt.Errorf("strict_port_binding rejected redirect to %s", req.URL.Host)
+	}
+	return nil
+}
+
+func (c *PolyCallClient) portBindingAllowed(u *url.URL) bool {
+	return u.Hostname() == c.host && u.Port() == fmt.Sprintf("%d", c.containerPort)
+}
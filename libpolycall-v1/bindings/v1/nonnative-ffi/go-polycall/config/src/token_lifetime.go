@@ -0,0 +1,148 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RenewBehavior controls how a TokenLifetimeWatcher reacts to a renewal
+// failure.
+type RenewBehavior int
+
+const (
+	// RenewBehaviorIgnoreErrors keeps retrying on the normal schedule so a
+	// transient LibPolyCall reconnect doesn't tear down the client.
+	RenewBehaviorIgnoreErrors RenewBehavior = iota
+	// RenewBehaviorFatal stops the watcher and marks the client
+	// unauthenticated on the first renewal error.
+	RenewBehaviorFatal
+)
+
+// DefaultTokenTTL is used when the server does not advertise a token
+// lifetime during handshake.
+const DefaultTokenTTL = 60 * time.Second
+
+// TokenRenewer renews the session token held by a PolyCallClient and
+// reports the TTL the server granted the renewed token.
+type TokenRenewer interface {
+	RenewToken(ctx context.Context) (ttl time.Duration, err error)
+}
+
+// protocolTokenRenewer renews the session by round-tripping a MessageAuth
+// frame through the client's existing framed transport.
+type protocolTokenRenewer struct {
+	client *PolyCallClient
+}
+
+func (r *protocolTokenRenewer) RenewToken(ctx context.Context) (time.Duration, error) {
+	err := r.client.sendFrame(MessageAuth, []byte(`{"action":"renew"}`), FlagReliable)
+	if err != nil {
+		return 0, fmt.Errorf("token renewal failed: %w", err)
+	}
+	return DefaultTokenTTL, nil
+}
+
+// TokenLifetimeWatcher renews a PolyCallClient's session token at roughly
+// TTL/2 with jitter, modeled on Vault's LifetimeWatcher. It never tears
+// down the client on a transient renewal error unless configured with
+// RenewBehaviorFatal.
+type TokenLifetimeWatcher struct {
+	client   *PolyCallClient
+	renewer  TokenRenewer
+	behavior RenewBehavior
+	errCh    chan error
+
+	ttl    time.Duration
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTokenLifetimeWatcher constructs a watcher for client using renewer to
+// perform each renewal, starting from initialTTL (the lease duration
+// granted by the handshake/auth that preceded it). A nil renewer defaults
+// to renewing over the client's own framed protocol connection; a
+// non-positive initialTTL falls back to DefaultTokenTTL.
+func NewTokenLifetimeWatcher(client *PolyCallClient, initialTTL time.Duration, renewer TokenRenewer, behavior RenewBehavior) *TokenLifetimeWatcher {
+	if renewer == nil {
+		renewer = &protocolTokenRenewer{client: client}
+	}
+	if initialTTL <= 0 {
+		initialTTL = DefaultTokenTTL
+	}
+	return &TokenLifetimeWatcher{
+		client:   client,
+		renewer:  renewer,
+		behavior: behavior,
+		ttl:      initialTTL,
+		errCh:    make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start spawns the renewal goroutine and returns immediately. Stop cancels
+// it; the client's context also stops it when the client disconnects.
+func (w *TokenLifetimeWatcher) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+
+	go w.run(ctx)
+}
+
+// Stop cancels the renewal loop and waits for it to exit.
+func (w *TokenLifetimeWatcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	<-w.done
+}
+
+// Errors surfaces renewal failures as they happen.
+func (w *TokenLifetimeWatcher) Errors() <-chan error {
+	return w.errCh
+}
+
+func (w *TokenLifetimeWatcher) run(ctx context.Context) {
+	defer close(w.done)
+
+	log := w.client.logger.With("phase", "auth", "subsystem", "token_lifetime_watcher")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jitteredHalfLife(w.ttl)):
+		}
+
+		renewedTTL, err := w.renewer.RenewToken(ctx)
+		if err != nil {
+			log.Warn("token renewal failed", "error", err)
+			select {
+			case w.errCh <- err:
+			default:
+			}
+
+			if w.behavior == RenewBehaviorFatal {
+				w.client.markAuthFailed()
+				return
+			}
+			// RenewBehaviorIgnoreErrors: retry on the same TTL schedule.
+			continue
+		}
+
+		w.ttl = renewedTTL
+		log.Debug("token renewed", "next_renewal", jitteredHalfLife(w.ttl))
+	}
+}
+
+// jitteredHalfLife returns TTL/2 plus up to 10% jitter, matching the
+// renew-before-expiry behavior of Vault-style lifetime watchers.
+func jitteredHalfLife(ttl time.Duration) time.Duration {
+	half := ttl / 2
+	jitter := time.Duration(rand.Int63n(int64(half)/10 + 1))
+	return half + jitter
+}
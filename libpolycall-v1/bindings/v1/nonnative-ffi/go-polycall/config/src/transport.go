@@ -0,0 +1,486 @@
+// Package polycall provides Go bindings for LibPolyCall
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package polycall
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+)
+
+// Transport abstracts how a PolyCallClient exchanges framed protocol
+// messages with a LibPolyCall peer. This mirrors the socket_client /
+// grpc_client split behind Tendermint's ABCI Client interface: the wire
+// format (ProtocolHeader + payload) and the message semantics in
+// PolyCallClient stay the same regardless of which Transport carries them.
+type Transport interface {
+	// Handshake establishes whatever connection the transport needs
+	// (TCP dial, HTTP reachability check, gRPC stream open) before Send/Recv
+	// can be used.
+	Handshake(ctx context.Context) error
+	// Send encodes and writes a single framed message under the given
+	// sequence number, which the caller allocates so it can correlate the
+	// eventual MessageResponse frame carrying the same Sequence back to
+	// this call.
+	Send(msgType MessageType, flags ProtocolFlag, sequence uint32, payload []byte) error
+	// Recv blocks for the next framed message, or until ctx is done.
+	Recv(ctx context.Context) (ProtocolHeader, []byte, error)
+	// Close releases the underlying connection. It is safe to call more
+	// than once.
+	Close() error
+}
+
+// newTransport picks a Transport implementation for host:port according to
+// the configuration's transport key (config.Transport), defaulting to the
+// tcp framed transport LibPolyCall has always used.
+func newTransport(config *Configuration, host string, port int, httpClient *http.Client) (Transport, error) {
+	switch config.Transport {
+	case "", "tcp":
+		return newTCPFramedTransport(host, port, config.TLS), nil
+	case "http":
+		return newHTTPTransport(host, port, httpClient), nil
+	case "grpc":
+		return newGRPCTransport(host, port), nil
+	case "ws", "websocket":
+		return newWSTransport(host, port), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q: want tcp, http, grpc, or ws", config.Transport)
+	}
+}
+
+// encodeFrame serializes header and payload into the wire format every
+// Transport implementation shares.
+func encodeFrame(header ProtocolHeader, payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, header); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+// frameHeader builds the ProtocolHeader for an outgoing message given the
+// transport's own sequence counter.
+func frameHeader(msgType MessageType, flags ProtocolFlag, sequence uint32, payload []byte) ProtocolHeader {
+	return ProtocolHeader{
+		Version:       ProtocolVersion,
+		Type:          msgType,
+		Flags:         flags,
+		Sequence:      sequence,
+		PayloadLength: uint32(len(payload)),
+		Checksum:      CalculateChecksum(payload),
+	}
+}
+
+// tcpFramedTransport is the original LibPolyCall transport: a single raw TCP
+// connection carrying ProtocolHeader-prefixed frames.
+type tcpFramedTransport struct {
+	host string
+	port int
+	tls  TLSConfig
+	conn net.Conn
+}
+
+func newTCPFramedTransport(host string, port int, tlsConfig TLSConfig) *tcpFramedTransport {
+	return &tcpFramedTransport{host: host, port: port, tls: tlsConfig}
+}
+
+func (t *tcpFramedTransport) Handshake(ctx context.Context) error {
+	conn, err := dialTCP(fmt.Sprintf("%s:%d", t.host, t.port), t.tls, DefaultTimeout)
+	if err != nil {
+		return fmt.Errorf("tcp transport dial failed: %w", err)
+	}
+	t.conn = conn
+	return nil
+}
+
+// dialTCP wraps net.DialTimeout with an optional TLS handshake: tlsConfig's
+// zero value (no CA/cert/key and InsecureSkipVerify false) dials plain TCP,
+// matching every caller's behavior before mTLS support existed; any other
+// value upgrades to tls.DialWithDialer so the [tls] config section actually
+// takes effect.
+func dialTCP(addr string, tlsConfig TLSConfig, timeout time.Duration) (net.Conn, error) {
+	if tlsConfig == (TLSConfig{}) {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+
+	conf, err := buildTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	return tls.DialWithDialer(dialer, "tcp", addr, conf)
+}
+
+// buildTLSConfig translates a [tls] config section into a *tls.Config: an
+// optional CA bundle to verify the server against, an optional client
+// cert/key for mTLS, and the InsecureSkipVerify/ServerName overrides an
+// operator may need for self-signed or SNI-mismatched deployments.
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	conf := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		ServerName:         cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		pemBytes, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("no certificates found in ca_file %s", cfg.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert/key: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return conf, nil
+}
+
+func (t *tcpFramedTransport) Send(msgType MessageType, flags ProtocolFlag, sequence uint32, payload []byte) error {
+	if t.conn == nil {
+		return fmt.Errorf("tcp transport not connected")
+	}
+	frame, err := encodeFrame(frameHeader(msgType, flags, sequence, payload), payload)
+	if err != nil {
+		return err
+	}
+	if _, err := t.conn.Write(frame); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (t *tcpFramedTransport) Recv(ctx context.Context) (ProtocolHeader, []byte, error) {
+	if t.conn == nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("tcp transport not connected")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetReadDeadline(deadline)
+	} else {
+		t.conn.SetReadDeadline(time.Now().Add(DefaultTimeout))
+	}
+
+	headerBytes := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(t.conn, headerBytes); err != nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	// Peek the payload length so we know how much more to read, without
+	// trusting it past MaxPayloadSize - DecodeFrame re-validates it below.
+	var peeked ProtocolHeader
+	if err := binary.Read(bytes.NewReader(headerBytes), binary.LittleEndian, &peeked); err != nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("failed to parse header: %w", err)
+	}
+	if peeked.PayloadLength > MaxPayloadSize {
+		return ProtocolHeader{}, nil, fmt.Errorf("payload size exceeds maximum: %d > %d", peeked.PayloadLength, MaxPayloadSize)
+	}
+
+	payload := make([]byte, peeked.PayloadLength)
+	if peeked.PayloadLength > 0 {
+		if _, err := io.ReadFull(t.conn, payload); err != nil {
+			return ProtocolHeader{}, nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+	}
+
+	return DecodeFrame(append(headerBytes, payload...))
+}
+
+func (t *tcpFramedTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// httpTransport carries the same frames over HTTP instead of a raw socket,
+// for peers that only expose LibPolyCall behind a REST-friendly ingress.
+// Since HTTP is request/response rather than full-duplex, each Send's
+// response frame is queued for the next Recv.
+type httpTransport struct {
+	baseURL string
+	client  *http.Client
+	frames  chan frameResult
+}
+
+type frameResult struct {
+	header  ProtocolHeader
+	payload []byte
+	err     error
+}
+
+func newHTTPTransport(host string, port int, client *http.Client) *httpTransport {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &httpTransport{
+		baseURL: fmt.Sprintf("http://%s:%d", host, port),
+		client:  client,
+		frames:  make(chan frameResult, 1),
+	}
+}
+
+func (t *httpTransport) Handshake(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("http transport handshake request failed: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http transport unreachable: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (t *httpTransport) Send(msgType MessageType, flags ProtocolFlag, sequence uint32, payload []byte) error {
+	frame, err := encodeFrame(frameHeader(msgType, flags, sequence, payload), payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.baseURL+"/__frame", bytes.NewReader(frame))
+	if err != nil {
+		return fmt.Errorf("failed to build frame request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("frame request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read frame response: %w", err)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	header, respPayload, err := DecodeFrame(body)
+	select {
+	case t.frames <- frameResult{header: header, payload: respPayload, err: err}:
+	default:
+		// A previous response was never collected by Recv; drop it rather
+		// than block the caller, matching at-most-one-in-flight semantics.
+	}
+	return nil
+}
+
+func (t *httpTransport) Recv(ctx context.Context) (ProtocolHeader, []byte, error) {
+	select {
+	case result := <-t.frames:
+		return result.header, result.payload, result.err
+	case <-ctx.Done():
+		return ProtocolHeader{}, nil, ctx.Err()
+	}
+}
+
+func (t *httpTransport) Close() error {
+	return nil
+}
+
+// wsTransport carries the same ProtocolHeader+payload frames over a single
+// gorilla/websocket connection, one frame per WebSocket binary message, for
+// peers reachable only through an HTTPS proxy or a browser-facing
+// LibPolyCall gateway where a raw TCP socket can't reach port 1234.
+type wsTransport struct {
+	url  string
+	conn *websocket.Conn
+}
+
+func newWSTransport(host string, port int) *wsTransport {
+	return &wsTransport{url: fmt.Sprintf("ws://%s:%d/", host, port)}
+}
+
+func (t *wsTransport) Handshake(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, t.url, nil)
+	if err != nil {
+		return fmt.Errorf("websocket transport dial failed: %w", err)
+	}
+	t.conn = conn
+	return nil
+}
+
+func (t *wsTransport) Send(msgType MessageType, flags ProtocolFlag, sequence uint32, payload []byte) error {
+	if t.conn == nil {
+		return fmt.Errorf("websocket transport not connected")
+	}
+	frame, err := encodeFrame(frameHeader(msgType, flags, sequence, payload), payload)
+	if err != nil {
+		return err
+	}
+	if err := t.conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+		return fmt.Errorf("websocket transport send failed: %w", err)
+	}
+	return nil
+}
+
+func (t *wsTransport) Recv(ctx context.Context) (ProtocolHeader, []byte, error) {
+	if t.conn == nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("websocket transport not connected")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetReadDeadline(deadline)
+	} else {
+		t.conn.SetReadDeadline(time.Now().Add(DefaultTimeout))
+	}
+	_, frame, err := t.conn.ReadMessage()
+	if err != nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("websocket transport recv failed: %w", err)
+	}
+	return DecodeFrame(frame)
+}
+
+func (t *wsTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// rawFrameCodecName is the gRPC content-subtype for grpcTransport's stream.
+// polycall.proto describes Frame as raw bytes (the same header+payload wire
+// format the tcp/http transports use) rather than a structured message, so
+// frames pass through as-is instead of going through protobuf marshaling.
+const rawFrameCodecName = "polycall-raw-frame"
+
+func init() {
+	encoding.RegisterCodec(rawFrameCodec{})
+}
+
+// rawFrame is the Go type rawFrameCodec marshals/unmarshals: an already
+// wire-encoded ProtocolHeader + payload frame.
+type rawFrame []byte
+
+type rawFrameCodec struct{}
+
+func (rawFrameCodec) Name() string { return rawFrameCodecName }
+
+func (rawFrameCodec) Marshal(v interface{}) ([]byte, error) {
+	frame, ok := v.(*rawFrame)
+	if !ok {
+		return nil, fmt.Errorf("polycall raw frame codec: unsupported type %T", v)
+	}
+	return []byte(*frame), nil
+}
+
+func (rawFrameCodec) Unmarshal(data []byte, v interface{}) error {
+	frame, ok := v.(*rawFrame)
+	if !ok {
+		return fmt.Errorf("polycall raw frame codec: unsupported type %T", v)
+	}
+	*frame = append((*frame)[:0], data...)
+	return nil
+}
+
+// grpcTransport carries frames over a bidirectional gRPC stream, following
+// the service described in polycall.proto:
+//
+//	service PolyCall {
+//	  rpc Stream(stream Frame) returns (stream Frame);
+//	}
+//
+// so SendCommand and state-transition calls can be multiplexed over one
+// HTTP/2 connection instead of one frame per TCP round trip.
+type grpcTransport struct {
+	target string
+	conn   *grpc.ClientConn
+	stream grpc.ClientStream
+}
+
+func newGRPCTransport(host string, port int) *grpcTransport {
+	return &grpcTransport{target: fmt.Sprintf("%s:%d", host, port)}
+}
+
+func (t *grpcTransport) Handshake(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(dialCtx, t.target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return fmt.Errorf("grpc transport dial failed: %w", err)
+	}
+	t.conn = conn
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    "Stream",
+		ClientStreams: true,
+		ServerStreams: true,
+	}, "/polycall.PolyCall/Stream", grpc.CallContentSubtype(rawFrameCodecName))
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("grpc transport stream open failed: %w", err)
+	}
+	t.stream = stream
+
+	return nil
+}
+
+func (t *grpcTransport) Send(msgType MessageType, flags ProtocolFlag, sequence uint32, payload []byte) error {
+	if t.stream == nil {
+		return fmt.Errorf("grpc transport not connected")
+	}
+	frame, err := encodeFrame(frameHeader(msgType, flags, sequence, payload), payload)
+	if err != nil {
+		return err
+	}
+	raw := rawFrame(frame)
+	if err := t.stream.SendMsg(&raw); err != nil {
+		return fmt.Errorf("grpc transport send failed: %w", err)
+	}
+	return nil
+}
+
+func (t *grpcTransport) Recv(ctx context.Context) (ProtocolHeader, []byte, error) {
+	if t.stream == nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("grpc transport not connected")
+	}
+	var raw rawFrame
+	if err := t.stream.RecvMsg(&raw); err != nil {
+		return ProtocolHeader{}, nil, fmt.Errorf("grpc transport recv failed: %w", err)
+	}
+	return DecodeFrame(raw)
+}
+
+func (t *grpcTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	t.stream = nil
+	return err
+}
@@ -0,0 +1,131 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// bookFixtures mirrors the kind of response TestHTTPAPIInteraction's
+// BookRetrieval subtest expects back from GetBooks.
+var bookFixtures = []map[string]interface{}{
+	{"title": "Aegis Protocol Design", "author": "Okpala", "year": "2023"},
+	{"title": "Program-First Architectures", "author": "Okpala", "year": "2024"},
+	{"title": "Zero Trust Handbook", "author": "Ada", "year": "2022"},
+}
+
+// TestEvaluateFilter validates the comparison and boolean grammar
+// ParseFilter/EvaluateFilter supports.
+func TestEvaluateFilter(t *testing.T) {
+	obj := map[string]interface{}{"title": "Aegis Protocol Design", "author": "Okpala", "year": "2023"}
+
+	cases := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"equal match", `author == "Okpala"`, true},
+		{"equal mismatch", `author == "Ada"`, false},
+		{"not equal", `author != "Ada"`, true},
+		{"matches", `title matches "^Aegis"`, true},
+		{"matches no match", `title matches "^Zero"`, false},
+		{"in", `year in ("2022", "2023")`, true},
+		{"not in", `year not in ("2022", "2024")`, true},
+		{"and true", `author == "Okpala" and title matches "^Aegis"`, true},
+		{"and false", `author == "Okpala" and title matches "^Zero"`, false},
+		{"or", `author == "Ada" or title matches "^Aegis"`, true},
+		{"not", `not (author == "Ada")`, true},
+		{"grouping", `(author == "Ada" or author == "Okpala") and year == "2023"`, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := polycall.EvaluateFilter(tc.expr, obj)
+			require.NoError(t, err, "filter %q should parse", tc.expr)
+			assert.Equal(t, tc.want, got, "filter %q against %v", tc.expr, obj)
+		})
+	}
+}
+
+// TestEvaluateFilterErrors validates malformed expressions surface a typed
+// parse error instead of a wrong answer.
+func TestEvaluateFilterErrors(t *testing.T) {
+	cases := []string{
+		`author ==`,
+		`author === "Okpala"`,
+		`title matches "[unterminated"`,
+		`author == "Okpala" and`,
+	}
+
+	for _, expr := range cases {
+		t.Run(expr, func(t *testing.T) {
+			_, err := polycall.EvaluateFilter(expr, map[string]interface{}{"author": "Okpala"})
+			assert.Error(t, err, "expected filter %q to fail to parse", expr)
+		})
+	}
+}
+
+// TestGetBooksFilter proves GetBooks narrows the book fixtures down to the
+// matching subset whether the server understands ?filter= (fixture already
+// narrowed, client-side re-filter is a no-op) or silently ignores it
+// (fixture returned in full, client-side re-filter does the work).
+func TestGetBooksFilter(t *testing.T) {
+	full, err := json.Marshal(bookFixtures)
+	require.NoError(t, err)
+
+	narrowed, err := json.Marshal(bookFixtures[:2])
+	require.NoError(t, err)
+
+	expr := `author == "Okpala" and title matches "^Aegis"`
+
+	t.Run("ServerIgnoresFilter", func(t *testing.T) {
+		filtered, err := serverSideFilterFallback(full, expr)
+		require.NoError(t, err)
+
+		var books []map[string]interface{}
+		require.NoError(t, json.Unmarshal(filtered, &books))
+		require.Len(t, books, 1)
+		assert.Equal(t, "Aegis Protocol Design", books[0]["title"])
+	})
+
+	t.Run("ServerAppliedFilter", func(t *testing.T) {
+		// A server that already honored ?filter= may return a narrower set;
+		// re-evaluating the same expression client-side must be a no-op.
+		filtered, err := serverSideFilterFallback(narrowed, expr)
+		require.NoError(t, err)
+
+		var books []map[string]interface{}
+		require.NoError(t, json.Unmarshal(filtered, &books))
+		require.Len(t, books, 1)
+		assert.Equal(t, "Aegis Protocol Design", books[0]["title"])
+	})
+}
+
+// serverSideFilterFallback exercises the same client-side evaluator
+// ListWithFilter applies to a raw JSON array response, without requiring a
+// running LibPolyCall server.
+func serverSideFilterFallback(body []byte, expr string) ([]byte, error) {
+	var items []map[string]interface{}
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+
+	matched := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		ok, err := polycall.EvaluateFilter(expr, item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+	return json.Marshal(matched)
+}
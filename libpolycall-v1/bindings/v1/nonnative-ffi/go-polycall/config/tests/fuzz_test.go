@@ -0,0 +1,119 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// encodeTestFrame builds a well-formed header+payload frame for seeding
+// FuzzFrameDecoding. It mirrors the wire format produced by
+// PolyCallClient.sendMessage without requiring a live connection.
+func encodeTestFrame(msgType polycall.MessageType, flags polycall.ProtocolFlag, payload []byte) []byte {
+	header := polycall.ProtocolHeader{
+		Version:       polycall.ProtocolVersion,
+		Type:          msgType,
+		Flags:         flags,
+		Sequence:      1,
+		PayloadLength: uint32(len(payload)),
+		Checksum:      polycall.CalculateChecksum(payload),
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, header)
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// seedConfigs are known-valid .polycallrc bodies used to seed FuzzConfigLoading.
+var seedConfigs = []string{
+	`port=3003:8083
+server_type=go
+workspace=/tmp/polycall/test
+log_level=debug
+max_connections=50
+supports_formatting=true
+max_memory=512M
+timeout=15
+allow_remote=false
+require_auth=true
+strict_port_binding=true
+go_version=1.21
+`,
+	`# comment-only line before a minimal config
+port=8080:8080
+`,
+	``,
+}
+
+// FuzzConfigLoading fuzzes polycall.NewPolyCallClient against arbitrary
+// .polycallrc bytes. Malformed config from an untrusted peer must never
+// panic and must always surface a typed error, per the zero-trust posture
+// documented on the polycall package.
+func FuzzConfigLoading(f *testing.F) {
+	for _, seed := range seedConfigs {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		configPath := filepath.Join(t.TempDir(), "fuzz.polycallrc")
+		if err := os.WriteFile(configPath, data, 0644); err != nil {
+			t.Fatalf("failed to write fuzz config: %v", err)
+		}
+
+		// NewPolyCallClient must never panic on arbitrary config bytes, and
+		// any rejection must come back as an error rather than a crash.
+		_, _ = polycall.NewPolyCallClient(configPath)
+	})
+}
+
+// seedFrames are well-formed protocol frames (header + payload) covering
+// every MessageType and a representative combination of ProtocolFlag bits.
+func seedFrames() [][]byte {
+	frames := [][]byte{}
+	types := []polycall.MessageType{
+		polycall.MessageHandshake,
+		polycall.MessageAuth,
+		polycall.MessageCommand,
+		polycall.MessageResponse,
+		polycall.MessageError,
+		polycall.MessageHeartbeat,
+	}
+	flags := []polycall.ProtocolFlag{
+		polycall.FlagNone,
+		polycall.FlagEncrypted | polycall.FlagReliable,
+		polycall.FlagCompressed | polycall.FlagUrgent,
+	}
+
+	for _, msgType := range types {
+		for _, flag := range flags {
+			frames = append(frames, encodeTestFrame(msgType, flag, []byte(`{"ping":true}`)))
+		}
+	}
+	return frames
+}
+
+// FuzzFrameDecoding fuzzes the TCP protocol frame decoder (header parsing,
+// payload length validation, checksum verification) against arbitrary
+// bytes read off the wire. Decoding must never panic, never trust an
+// attacker-controlled PayloadLength into an unbounded allocation, and
+// always fail with a typed error on malformed input.
+func FuzzFrameDecoding(f *testing.F) {
+	for _, frame := range seedFrames() {
+		f.Add(frame)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// DecodeFrame must never panic regardless of how data is truncated,
+		// oversized, or corrupted; it must bound any length it trusts from
+		// the header against MaxPayloadSize before allocating.
+		_, _, _ = polycall.DecodeFrame(data)
+	})
+}
@@ -0,0 +1,145 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// fakeAuthServer accepts TCP connections on a loopback port and answers
+// every MessageAuth frame it sees with a MessageResponse carrying
+// {"authenticated":true}, enough for PolyCallClient's handshake+auth
+// exchange to succeed without a real polycall.exe runtime. It never
+// replies to MessageHeartbeat frames, so a KeepAliveWatcher attached to a
+// client dialing it will eventually see a heartbeat timeout and drive its
+// reconnect loop.
+type fakeAuthServer struct {
+	listener net.Listener
+}
+
+func startFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeAuthServer{listener: listener}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeAuthServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeAuthServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeAuthServer) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		headerBytes := make([]byte, polycall.HeaderSize)
+		if _, err := io.ReadFull(conn, headerBytes); err != nil {
+			return
+		}
+
+		var header polycall.ProtocolHeader
+		if err := binary.Read(bytes.NewReader(headerBytes), binary.LittleEndian, &header); err != nil {
+			return
+		}
+
+		payload := make([]byte, header.PayloadLength)
+		if header.PayloadLength > 0 {
+			if _, err := io.ReadFull(conn, payload); err != nil {
+				return
+			}
+		}
+
+		if header.Type != polycall.MessageAuth {
+			continue
+		}
+
+		respPayload, err := json.Marshal(map[string]interface{}{"authenticated": true})
+		if err != nil {
+			return
+		}
+		respHeader := polycall.ProtocolHeader{
+			Version:       polycall.ProtocolVersion,
+			Type:          polycall.MessageResponse,
+			Sequence:      header.Sequence,
+			PayloadLength: uint32(len(respPayload)),
+			Checksum:      polycall.CalculateChecksum(respPayload),
+		}
+
+		var out bytes.Buffer
+		if err := binary.Write(&out, binary.LittleEndian, respHeader); err != nil {
+			return
+		}
+		out.Write(respPayload)
+		if _, err := conn.Write(out.Bytes()); err != nil {
+			return
+		}
+	}
+}
+
+// TestKeepAliveEventsObservesReconnect drives a real Connect against
+// fakeAuthServer with a short heartbeat timeout, then proves
+// KeepAliveEvents surfaces the disconnect/reconnect cycle the background
+// KeepAliveWatcher runs once the server stops answering heartbeats -
+// KeepAliveEvents is the only way a caller outside config/src can reach
+// that watcher's channel at all.
+func TestKeepAliveEventsObservesReconnect(t *testing.T) {
+	server := startFakeAuthServer(t)
+
+	configPath := filepath.Join(t.TempDir(), "keepalive.polycallrc")
+	config := fmt.Sprintf("port=3003:%d\nstrict_port_binding=false\n", server.port())
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+	client, err := polycall.NewPolyCallClient(configPath, polycall.WithKeepAlive(10*time.Millisecond, 30*time.Millisecond))
+	require.NoError(t, err, "Failed to create client")
+	defer client.Disconnect()
+
+	require.Nil(t, client.KeepAliveEvents(), "KeepAliveEvents should be nil before Connect")
+
+	require.NoError(t, client.Connect(), "Connect should succeed against fakeAuthServer")
+
+	events := client.KeepAliveEvents()
+	require.NotNil(t, events, "KeepAliveEvents should be non-nil once WithKeepAlive is configured and connected")
+
+	var sawDisconnected, sawReconnecting bool
+	timeout := time.After(TestTimeout)
+	for !sawDisconnected || !sawReconnecting {
+		select {
+		case event := <-events:
+			switch event.State {
+			case polycall.StateDisconnected:
+				sawDisconnected = true
+			case polycall.StateReconnecting:
+				sawReconnecting = true
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for reconnect cycle: disconnected=%v reconnecting=%v", sawDisconnected, sawReconnecting)
+		}
+	}
+}
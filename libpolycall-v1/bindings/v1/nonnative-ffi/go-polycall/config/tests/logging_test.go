@@ -0,0 +1,61 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// TestStructuredLogging validates that client lifecycle events emit
+// structured hclog records instead of t.Logf breadcrumbs.
+func TestStructuredLogging(t *testing.T) {
+	t.Run("FailedConnectEmitsConnectPhase", func(t *testing.T) {
+		var sink strings.Builder
+		logger := hclog.New(&hclog.LoggerOptions{
+			Name:   "test",
+			Level:  hclog.Debug,
+			Output: &sink,
+		})
+
+		configPath := writeTempConfig(t, "port=3003:8083\n")
+		client, err := polycall.NewPolyCallClient(configPath, polycall.WithLogger(logger))
+		require.NoError(t, err, "Failed to create client with custom logger")
+
+		// No LibPolyCall server is running in this test environment, so
+		// Connect() should fail at the TCP dial and emit a structured
+		// connect-phase error record rather than panicking or going silent.
+		err = client.Connect()
+		assert.Error(t, err, "Connect should fail without a running server")
+
+		output := sink.String()
+		assert.Contains(t, output, "phase=connect", "Log output should carry the connect phase field")
+	})
+
+	t.Run("DefaultLoggerUsesDebugJSONFromConfig", func(t *testing.T) {
+		configContent := `port=3003:8083
+log_level=debug
+`
+		configPath := writeTempConfig(t, configContent)
+
+		client, err := polycall.NewPolyCallClient(configPath)
+		require.NoError(t, err, "Failed to create client with debug log level")
+		assert.NotNil(t, client, "Client should not be nil")
+	})
+}
+
+func writeTempConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logging_test.polycallrc")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
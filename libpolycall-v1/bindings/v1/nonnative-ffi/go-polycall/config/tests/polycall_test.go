@@ -13,7 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	polycall "../src"
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
 )
 
 // Test configuration constants
@@ -26,7 +26,7 @@ const (
 func TestConfiguration(t *testing.T) {
 	t.Run("DefaultConfiguration", func(t *testing.T) {
 		// Test default configuration creation
-		client, err := polycall.NewPolyCallClient()
+		client, err := polycall.NewPolyCallClient("")
 		require.NoError(t, err, "Failed to create client with default configuration")
 		assert.NotNil(t, client, "Client should not be nil")
 	})
@@ -68,7 +68,7 @@ go_version=1.21
 
 // TestClientLifecycle validates connection management
 func TestClientLifecycle(t *testing.T) {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	require.NoError(t, err, "Failed to create client")
 
 	t.Run("InitialState", func(t *testing.T) {
@@ -100,7 +100,7 @@ func TestClientLifecycle(t *testing.T) {
 
 // TestHTTPAPIInteraction validates HTTP-based API communication
 func TestHTTPAPIInteraction(t *testing.T) {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	require.NoError(t, err, "Failed to create client")
 
 	t.Run("BookCreation", func(t *testing.T) {
@@ -148,7 +148,7 @@ func TestHTTPAPIInteraction(t *testing.T) {
 
 // TestStateManagement validates state management functionality
 func TestStateManagement(t *testing.T) {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	require.NoError(t, err, "Failed to create client")
 
 	t.Run("StateRetrieval", func(t *testing.T) {
@@ -199,7 +199,7 @@ func TestStateManagement(t *testing.T) {
 
 // TestProtocolHandling validates protocol-level functionality
 func TestProtocolHandling(t *testing.T) {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	require.NoError(t, err, "Failed to create client")
 
 	t.Run("CommandExecution", func(t *testing.T) {
@@ -237,7 +237,7 @@ func TestErrorHandling(t *testing.T) {
 	})
 
 	t.Run("NetworkTimeouts", func(t *testing.T) {
-		client, err := polycall.NewPolyCallClient()
+		client, err := polycall.NewPolyCallClient("")
 		require.NoError(t, err)
 
 		// Test with non-existent server (should timeout)
@@ -250,7 +250,7 @@ func TestErrorHandling(t *testing.T) {
 
 // TestConcurrency validates thread-safety and concurrent operations
 func TestConcurrency(t *testing.T) {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	require.NoError(t, err, "Failed to create client")
 
 	t.Run("ConcurrentAPIRequests", func(t *testing.T) {
@@ -311,7 +311,7 @@ func TestConcurrency(t *testing.T) {
 
 // BenchmarkHTTPRequests measures performance of HTTP requests
 func BenchmarkHTTPRequests(b *testing.B) {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	if err != nil {
 		b.Fatalf("Failed to create client: %v", err)
 	}
@@ -334,7 +334,7 @@ func BenchmarkHTTPRequests(b *testing.B) {
 // Helper functions for testing
 
 func createTestClient(t *testing.T) *polycall.PolyCallClient {
-	client, err := polycall.NewPolyCallClient()
+	client, err := polycall.NewPolyCallClient("")
 	require.NoError(t, err, "Failed to create test client")
 	return client
 }
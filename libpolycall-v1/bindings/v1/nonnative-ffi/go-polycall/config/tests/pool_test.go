@@ -0,0 +1,110 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// TestConcurrencyPoolBackpressure is the max_connections=2 variant of
+// TestConcurrency/ConcurrentAPIRequests: against a real (httptest) server,
+// it asserts the client never holds more than max_connections requests in
+// flight at once, and that the rest queue instead of erroring out.
+func TestConcurrencyPoolBackpressure(t *testing.T) {
+	var inFlight, maxObserved int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&maxObserved)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxObserved, old, cur) {
+				break
+			}
+		}
+
+		<-release
+
+		atomic.AddInt32(&inFlight, -1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "pool_test.polycallrc")
+	content := fmt.Sprintf("port=%s:%s\nmax_connections=2\nstrict_port_binding=false\n", port, port)
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	client, err := polycall.NewPolyCallClient(configPath)
+	require.NoError(t, err, "Failed to create client")
+
+	const numRequests = 5
+	var wg sync.WaitGroup
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.GetBooks()
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&inFlight) == 2
+	}, TestTimeout, 5*time.Millisecond, "exactly max_connections requests should be in flight, the rest should queue")
+
+	assert.EqualValues(t, 2, client.Metrics().RequestsInFlight, "client.Metrics() should agree with the server's view")
+
+	close(release)
+	wg.Wait()
+
+	assert.LessOrEqual(t, int(maxObserved), 2, "no more than max_connections requests should ever run concurrently")
+	assert.EqualValues(t, 0, client.Metrics().RequestsInFlight, "pool should be empty once every request completes")
+}
+
+// TestPortBindingRejectsRedirect proves strict_port_binding counts and
+// refuses a redirect off the negotiated host:port, rather than silently
+// following it.
+func TestPortBindingRejectsRedirect(t *testing.T) {
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer elsewhere.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, elsewhere.URL+"/books", http.StatusFound)
+	}))
+	defer server.Close()
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "redirect_test.polycallrc")
+	content := fmt.Sprintf("port=%s:%s\nstrict_port_binding=true\n", port, port)
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	client, err := polycall.NewPolyCallClient(configPath)
+	require.NoError(t, err, "Failed to create client")
+
+	_, err = client.GetBooks()
+	assert.Error(t, err, "redirect off the negotiated port should be rejected")
+	assert.EqualValues(t, 1, client.Metrics().PortBindingRejectionsTotal)
+}
@@ -0,0 +1,100 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// fakeTokenRenewer grants a short TTL every renewal and counts how many
+// times it was asked to renew, standing in for a real token endpoint.
+type fakeTokenRenewer struct {
+	ttl      time.Duration
+	renewals int32
+}
+
+func (f *fakeTokenRenewer) RenewToken(ctx context.Context) (time.Duration, error) {
+	atomic.AddInt32(&f.renewals, 1)
+	return f.ttl, nil
+}
+
+// TestTokenLifetimeWatcherRenewsRepeatedly proves the watcher renews at
+// least N times within TestTimeout against a fake token endpoint with a
+// short TTL.
+func TestTokenLifetimeWatcherRenewsRepeatedly(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "token_lifetime.polycallrc")
+	require.NoError(t, os.WriteFile(configPath, []byte("port=3003:8083\n"), 0644))
+
+	client, err := polycall.NewPolyCallClient(configPath)
+	require.NoError(t, err, "Failed to create client")
+
+	renewer := &fakeTokenRenewer{ttl: 20 * time.Millisecond}
+	watcher := polycall.NewTokenLifetimeWatcher(client, 20*time.Millisecond, renewer, polycall.RenewBehaviorIgnoreErrors)
+
+	ctx, cancel := context.WithTimeout(context.Background(), TestTimeout)
+	defer cancel()
+
+	watcher.Start(ctx)
+	defer watcher.Stop()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&renewer.renewals) >= 3
+	}, TestTimeout, 5*time.Millisecond, "watcher should renew the token at least 3 times")
+}
+
+// failingTokenRenewer always fails, standing in for a token endpoint that's
+// gone unreachable.
+type failingTokenRenewer struct{}
+
+func (f *failingTokenRenewer) RenewToken(ctx context.Context) (time.Duration, error) {
+	return 0, fmt.Errorf("simulated renewal failure")
+}
+
+// TestTokenLifetimeWatcherFatalBehaviorMarksUnauthenticated proves
+// RenewBehaviorFatal - selectable via WithRenewBehavior since
+// NewPolyCallClient's handshake no longer hardcodes
+// RenewBehaviorIgnoreErrors - actually marks a connected client
+// unauthenticated on its first renewal error, unlike
+// TestTokenLifetimeWatcherRenewsRepeatedly's RenewBehaviorIgnoreErrors case.
+func TestTokenLifetimeWatcherFatalBehaviorMarksUnauthenticated(t *testing.T) {
+	server := startFakeAuthServer(t)
+
+	configPath := filepath.Join(t.TempDir(), "token_lifetime_fatal.polycallrc")
+	config := fmt.Sprintf("port=3003:%d\nstrict_port_binding=false\n", server.port())
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+	client, err := polycall.NewPolyCallClient(configPath, polycall.WithRenewBehavior(polycall.RenewBehaviorFatal))
+	require.NoError(t, err, "Failed to create client")
+	defer client.Disconnect()
+
+	require.NoError(t, client.Connect(), "Connect should succeed against fakeAuthServer")
+	require.True(t, client.IsAuthenticated(), "client should be authenticated once Connect succeeds")
+
+	// handshake's own watcher renews on a 60s schedule (DefaultTokenTTL),
+	// far past TestTimeout, so drive a second watcher directly against the
+	// same client with a fast TTL and a renewer that always fails - the
+	// same "construct our own watcher" technique
+	// TestTokenLifetimeWatcherRenewsRepeatedly uses to avoid waiting on a
+	// real token endpoint.
+	ctx, cancel := context.WithTimeout(context.Background(), TestTimeout)
+	defer cancel()
+
+	watcher := polycall.NewTokenLifetimeWatcher(client, 10*time.Millisecond, &failingTokenRenewer{}, polycall.RenewBehaviorFatal)
+	watcher.Start(ctx)
+	defer watcher.Stop()
+
+	require.Eventually(t, func() bool {
+		return !client.IsAuthenticated()
+	}, TestTimeout, 5*time.Millisecond, "a RenewBehaviorFatal renewal error should mark the client unauthenticated")
+}
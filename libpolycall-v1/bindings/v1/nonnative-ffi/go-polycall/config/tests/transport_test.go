@@ -0,0 +1,61 @@
+// Package tests provides comprehensive testing for Go PolyCall binding
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	polycall "github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/config/src"
+)
+
+// TestTransportSelection runs the same connect-without-a-server shape as
+// TestClientLifecycle/TestHTTPAPIInteraction/TestStateManagement against
+// every transport key NewPolyCallClient accepts, proving the tcp, http, and
+// grpc transports all build successfully and fail the same way - a
+// connection error, never a panic or silent no-op - when no LibPolyCall
+// peer is listening.
+func TestTransportSelection(t *testing.T) {
+	transports := []string{"tcp", "http", "grpc"}
+
+	for _, transport := range transports {
+		t.Run(transport, func(t *testing.T) {
+			configPath := writeTransportConfig(t, transport)
+
+			client, err := polycall.NewPolyCallClient(configPath)
+			require.NoError(t, err, "Failed to create client for transport %q", transport)
+			assert.NotNil(t, client, "Client should not be nil")
+			assert.False(t, client.IsConnected(), "Client should not be connected initially")
+
+			// No LibPolyCall server is running in this test environment, so
+			// every transport should fail the same way: a wrapped connect
+			// error, not a panic or a silent success.
+			err = client.Connect()
+			assert.Error(t, err, "Connect should fail without a running server")
+			assert.False(t, client.IsConnected(), "Client should remain disconnected on connection failure")
+		})
+	}
+}
+
+// TestUnknownTransportRejected proves an unrecognized transport key fails
+// fast at construction time instead of being silently ignored.
+func TestUnknownTransportRejected(t *testing.T) {
+	configPath := writeTransportConfig(t, "carrier_pigeon")
+
+	_, err := polycall.NewPolyCallClient(configPath)
+	assert.Error(t, err, "Should reject an unknown transport key")
+}
+
+func writeTransportConfig(t *testing.T, transport string) string {
+	t.Helper()
+	content := fmt.Sprintf("port=3003:8083\ntransport=%s\n", transport)
+	path := filepath.Join(t.TempDir(), "transport_test.polycallrc")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
@@ -0,0 +1,103 @@
+// Package httputil provides HTTP-layer helpers shared by any server-side
+// handlers fronting polycall.exe's HTTP surface (the book/state endpoints
+// config/examples/example_client.go's demonstrateHTTPAPI exercises) and by
+// pkg.Client callers that want to attribute telemetry to the real caller
+// rather than the last proxy hop.
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package httputil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxyConfig lists the CIDR ranges ClientIP treats as trusted
+// reverse proxies: hops inside these ranges are skipped when walking
+// X-Forwarded-For, since they're expected to be our own infrastructure
+// rather than the real caller.
+type TrustedProxyConfig struct {
+	TrustedCIDRs []*net.IPNet
+}
+
+// DefaultTrustedProxyConfig trusts loopback and the RFC1918 private ranges,
+// the common case for a reverse proxy sitting on the same host or in the
+// same private network as polycall.exe.
+func DefaultTrustedProxyConfig() TrustedProxyConfig {
+	var cfg TrustedProxyConfig
+	for _, cidr := range []string{
+		"127.0.0.0/8",
+		"::1/128",
+		"10.0.0.0/8",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("httputil: invalid built-in CIDR %q: %v", cidr, err))
+		}
+		cfg.TrustedCIDRs = append(cfg.TrustedCIDRs, network)
+	}
+	return cfg
+}
+
+// trusted reports whether ip falls inside any of cfg's trusted ranges.
+func (cfg TrustedProxyConfig) trusted(ip net.IP) bool {
+	for _, network := range cfg.TrustedCIDRs {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP resolves the real caller's IP for a request that may have passed
+// through one or more reverse proxies. The immediate hop, r.RemoteAddr, is
+// trusted first: only when it falls inside cfg's trusted ranges are
+// X-Real-IP/X-Forwarded-For honored at all, since otherwise any direct
+// caller could set those headers itself and have them believed verbatim.
+// Once RemoteAddr is trusted, X-Real-IP is preferred outright as an
+// explicit operator override; otherwise X-Forwarded-For is walked
+// right-to-left (the order proxies append in), skipping any hop inside
+// cfg's trusted ranges. RemoteAddr itself is the fallback whenever it
+// isn't trusted, or every header candidate is trusted or unparsable.
+func ClientIP(r *http.Request, cfg TrustedProxyConfig) net.IP {
+	remote := remoteIP(r)
+	if remote == nil || !cfg.trusted(remote) {
+		return remote
+	}
+
+	if real := r.Header.Get("X-Real-IP"); real != "" {
+		if ip := net.ParseIP(strings.TrimSpace(real)); ip != nil {
+			return ip
+		}
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				continue
+			}
+			if !cfg.trusted(ip) {
+				return ip
+			}
+		}
+	}
+
+	return remote
+}
+
+// remoteIP parses r.RemoteAddr, which is normally "host:port" but is
+// parsed as a bare host if SplitHostPort fails (e.g. in tests that set it
+// directly to an IP with no port).
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
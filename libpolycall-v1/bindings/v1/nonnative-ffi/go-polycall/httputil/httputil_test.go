@@ -0,0 +1,54 @@
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package httputil
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequest(remoteAddr, xRealIP, xForwardedFor string) *http.Request {
+	r := &http.Request{Header: make(http.Header), RemoteAddr: remoteAddr}
+	if xRealIP != "" {
+		r.Header.Set("X-Real-IP", xRealIP)
+	}
+	if xForwardedFor != "" {
+		r.Header.Set("X-Forwarded-For", xForwardedFor)
+	}
+	return r
+}
+
+func TestClientIPUntrustedRemoteIgnoresHeaders(t *testing.T) {
+	// A direct attacker dialing in from a public IP can set whatever
+	// X-Real-IP it likes; since 203.0.113.5 isn't in any trusted range,
+	// that header must be ignored and the real socket peer returned.
+	r := newRequest("203.0.113.5:54321", "10.0.0.99", "")
+	got := ClientIP(r, DefaultTrustedProxyConfig())
+	assert.Equal(t, "203.0.113.5", got.String())
+}
+
+func TestClientIPTrustedProxyHonorsXRealIP(t *testing.T) {
+	r := newRequest("127.0.0.1:54321", "198.51.100.7", "")
+	got := ClientIP(r, DefaultTrustedProxyConfig())
+	assert.Equal(t, "198.51.100.7", got.String())
+}
+
+func TestClientIPTrustedProxyWalksForwardedForSkippingTrustedHops(t *testing.T) {
+	r := newRequest("10.0.0.1:54321", "", "198.51.100.7, 10.0.0.2, 10.0.0.1")
+	got := ClientIP(r, DefaultTrustedProxyConfig())
+	assert.Equal(t, "198.51.100.7", got.String())
+}
+
+func TestClientIPTrustedProxyAllHopsTrustedFallsBackToRemoteAddr(t *testing.T) {
+	r := newRequest("10.0.0.1:54321", "", "10.0.0.2, 10.0.0.1")
+	got := ClientIP(r, DefaultTrustedProxyConfig())
+	assert.Equal(t, "10.0.0.1", got.String())
+}
+
+func TestClientIPRemoteAddrWithoutPort(t *testing.T) {
+	r := newRequest("203.0.113.5", "", "")
+	got := ClientIP(r, DefaultTrustedProxyConfig())
+	assert.Equal(t, "203.0.113.5", got.String())
+}
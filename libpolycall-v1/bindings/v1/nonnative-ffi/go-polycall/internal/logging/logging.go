@@ -0,0 +1,94 @@
+// Package logging is the go-polycall binding's structured logging facade.
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+//
+// It wraps github.com/hashicorp/go-hclog (already the logging dependency
+// config/src/polycall_client.go uses directly) behind a narrower, binding-
+// owned interface, so pkg.Client and its transport/protocol/telemetry
+// collaborators depend on this package rather than on hclog's full API
+// surface.
+package logging
+
+import (
+	"io"
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Logger is the structured logging interface every polycall binding
+// component (Client, its transport, protocol handler, and telemetry
+// observer) takes, instead of calling fmt.Println/log.Printf or recording
+// bare event-name strings. kv is alternating key/value pairs, hclog-style
+// (e.g. Info("handshake complete", "state", "READY", "elapsed_ms", 12)).
+type Logger interface {
+	Trace(msg string, kv ...interface{})
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warn(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+
+	// With returns a Logger that prepends kv to every subsequent record,
+	// for attaching fixed context (e.g. correlation IDs) once per scope.
+	With(kv ...interface{}) Logger
+	// Named returns a Logger whose records are tagged under sub, nested
+	// under any name this Logger already carries (e.g. "polycall.transport").
+	Named(sub string) Logger
+}
+
+// Format selects Logger's output encoding.
+type Format int
+
+const (
+	// FormatText is hclog's default human-readable output.
+	FormatText Format = iota
+	// FormatJSON emits one JSON object per record, for log aggregation.
+	FormatJSON
+)
+
+// Options configures New.
+type Options struct {
+	Name   string    // root logger name, e.g. "polycall"
+	Level  string    // trace, debug, info, warn, error; defaults to info
+	Format Format    // FormatText or FormatJSON
+	Output io.Writer // defaults to os.Stderr
+}
+
+// New builds a Logger from opts, defaulting Level to info and Output to
+// os.Stderr when unset.
+func New(opts Options) Logger {
+	level := hclog.LevelFromString(opts.Level)
+	if level == hclog.NoLevel {
+		level = hclog.Info
+	}
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	return &hclogAdapter{hclog.New(&hclog.LoggerOptions{
+		Name:       opts.Name,
+		Level:      level,
+		Output:     output,
+		JSONFormat: opts.Format == FormatJSON,
+	})}
+}
+
+// hclogAdapter implements Logger by delegating to an hclog.Logger.
+type hclogAdapter struct {
+	l hclog.Logger
+}
+
+func (a *hclogAdapter) Trace(msg string, kv ...interface{}) { a.l.Trace(msg, kv...) }
+func (a *hclogAdapter) Debug(msg string, kv ...interface{}) { a.l.Debug(msg, kv...) }
+func (a *hclogAdapter) Info(msg string, kv ...interface{})  { a.l.Info(msg, kv...) }
+func (a *hclogAdapter) Warn(msg string, kv ...interface{})  { a.l.Warn(msg, kv...) }
+func (a *hclogAdapter) Error(msg string, kv ...interface{}) { a.l.Error(msg, kv...) }
+
+func (a *hclogAdapter) With(kv ...interface{}) Logger {
+	return &hclogAdapter{a.l.With(kv...)}
+}
+
+func (a *hclogAdapter) Named(sub string) Logger {
+	return &hclogAdapter{a.l.Named(sub)}
+}
@@ -0,0 +1,323 @@
+package internal
+
+// Plugin-backed feature execution, modeled on hashicorp/go-plugin: each
+// feature can be served by a separate binary the Client launches, rather
+// than only by the built-in ProtocolHandler path. A plugin binary writes a
+// single magic-cookie-gated handshake line to stdout naming the unix
+// socket it's listening on; the Client dials that socket with a
+// grpcTransport (SchemeGRPC already speaks raw frames over a unix-socket
+// gRPC stream, so no separate wire format is needed here) and drives a
+// small JSON request/response RPC over it.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPluginProtocolVersion is the protocol version WithPluginDir's
+// auto-discovery negotiates; RegisterPluginFeature lets a caller pin a
+// different one per plugin.
+const DefaultPluginProtocolVersion = 1
+
+const (
+	pluginMagicCookieKey   = "POLYCALL_PLUGIN_MAGIC_COOKIE"
+	pluginMagicCookieValue = "polycall-plugin-v1"
+	pluginHandshakeTimeout = 10 * time.Second
+)
+
+// FeatureResult mirrors pkg.ExecutionResult's fields without importing the
+// pkg package (which already imports this one), so ProtocolHandler and
+// FeatureProvider can share a return type; Client.ExecuteFeature converts
+// whichever produced it into its own ExecutionResult.
+type FeatureResult struct {
+	FeatureName string
+	Success     bool
+	Data        map[string]interface{}
+	Duration    time.Duration
+}
+
+// FeatureProvider executes one feature. PluginProcess is the only
+// implementation today; tests can supply their own.
+type FeatureProvider interface {
+	Execute(ctx context.Context, params map[string]interface{}) (*FeatureResult, error)
+}
+
+// FeatureRegistry resolves a feature name to the FeatureProvider that
+// should run it, so Client.ExecuteFeature doesn't need to know whether a
+// feature is plugin-backed or built-in.
+type FeatureRegistry interface {
+	Resolve(name string) (FeatureProvider, bool)
+}
+
+// pluginHandshake is what a plugin binary's single stdout handshake line
+// decodes to: "<magic>|<protoVersion>|<network>|<address>". Only unix is
+// supported, matching WithPluginDir/RegisterPluginFeature's "polycall.exe
+// runtimes over a local socket" scope.
+type pluginHandshake struct {
+	ProtocolVersion int
+	SocketPath      string
+}
+
+func parsePluginHandshake(line string) (pluginHandshake, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 {
+		return pluginHandshake{}, fmt.Errorf("malformed handshake line %q", line)
+	}
+	if parts[0] != pluginMagicCookieValue {
+		return pluginHandshake{}, fmt.Errorf("unexpected magic cookie %q", parts[0])
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return pluginHandshake{}, fmt.Errorf("invalid protocol version %q: %w", parts[1], err)
+	}
+	if parts[2] != "unix" {
+		return pluginHandshake{}, fmt.Errorf("unsupported network %q: only unix is supported", parts[2])
+	}
+	return pluginHandshake{ProtocolVersion: version, SocketPath: parts[3]}, nil
+}
+
+// readHandshakeLine reads r's first line, bounded by timeout so a plugin
+// binary that never handshakes can't hang Start forever.
+func readHandshakeLine(r io.Reader, timeout time.Duration) (string, error) {
+	lineCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		if scanner.Scan() {
+			lineCh <- scanner.Text()
+			return
+		}
+		if err := scanner.Err(); err != nil {
+			errCh <- err
+			return
+		}
+		errCh <- io.ErrUnexpectedEOF
+	}()
+
+	select {
+	case line := <-lineCh:
+		return line, nil
+	case err := <-errCh:
+		return "", err
+	case <-time.After(timeout):
+		return "", fmt.Errorf("timed out after %s waiting for handshake", timeout)
+	}
+}
+
+// pluginRequest/pluginResponse are the JSON frames PluginProcess exchanges
+// with a plugin binary over its grpcTransport.
+type pluginRequest struct {
+	Feature string                 `json:"feature"`
+	Params  map[string]interface{} `json:"params"`
+}
+
+type pluginResponse struct {
+	Success   bool                   `json:"success"`
+	Data      map[string]interface{} `json:"data"`
+	Error     string                 `json:"error,omitempty"`
+	Telemetry []PluginTelemetryEvent `json:"telemetry,omitempty"`
+}
+
+// PluginTelemetryEvent is one event a plugin reports back on its response's
+// side channel, fed into TelemetryObserver the same way Client's own
+// RecordEvent calls are.
+type PluginTelemetryEvent struct {
+	Name string                 `json:"name"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// PluginProcess is a FeatureProvider backed by a separate binary: cmd is
+// launched lazily on the feature's first Execute call, handshakes over
+// stdout, and is dialed over a unix-socket Transport for every call after.
+type PluginProcess struct {
+	name         string
+	cmd          *exec.Cmd
+	protoVersion int
+	telemetry    *TelemetryObserver
+
+	startOnce sync.Once
+	startErr  error
+	transport Transport
+}
+
+// NewPluginProcess builds a PluginProcess for name; cmd isn't started until
+// the feature's first Execute call. telemetry may be nil (events are
+// simply dropped), matching TelemetryObserver's own non-intrusive design.
+func NewPluginProcess(name string, cmd *exec.Cmd, protoVersion int, telemetry *TelemetryObserver) *PluginProcess {
+	return &PluginProcess{name: name, cmd: cmd, protoVersion: protoVersion, telemetry: telemetry}
+}
+
+// Start launches the plugin binary, reads its handshake line, and dials
+// the unix socket it advertised. Called at most once per PluginProcess
+// (via ensureStarted); a failed Start's error is returned on every
+// subsequent Execute without relaunching the binary.
+func (p *PluginProcess) Start(ctx context.Context) error {
+	p.cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", pluginMagicCookieKey, pluginMagicCookieValue))
+
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("plugin %s: failed to attach stdout: %w", p.name, err)
+	}
+	if err := p.cmd.Start(); err != nil {
+		return fmt.Errorf("plugin %s: failed to start: %w", p.name, err)
+	}
+
+	line, err := readHandshakeLine(stdout, pluginHandshakeTimeout)
+	if err != nil {
+		p.cmd.Process.Kill()
+		return fmt.Errorf("plugin %s: handshake failed: %w", p.name, err)
+	}
+
+	handshake, err := parsePluginHandshake(line)
+	if err != nil {
+		p.cmd.Process.Kill()
+		return fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+	if handshake.ProtocolVersion != p.protoVersion {
+		p.cmd.Process.Kill()
+		return fmt.Errorf("plugin %s: protocol version mismatch: want %d, got %d", p.name, p.protoVersion, handshake.ProtocolVersion)
+	}
+
+	transport, err := NewTransportForScheme(SchemeGRPC, "unix:"+handshake.SocketPath)
+	if err != nil {
+		p.cmd.Process.Kill()
+		return fmt.Errorf("plugin %s: transport init failed: %w", p.name, err)
+	}
+	if err := transport.Dial(ctx, ""); err != nil {
+		p.cmd.Process.Kill()
+		return fmt.Errorf("plugin %s: dial failed: %w", p.name, err)
+	}
+	p.transport = transport
+	return nil
+}
+
+func (p *PluginProcess) ensureStarted(ctx context.Context) error {
+	p.startOnce.Do(func() {
+		p.startErr = p.Start(ctx)
+	})
+	return p.startErr
+}
+
+// Execute sends params to the plugin and waits for its response, replaying
+// any side-channel telemetry the plugin attached before returning the
+// feature's own result.
+func (p *PluginProcess) Execute(ctx context.Context, params map[string]interface{}) (*FeatureResult, error) {
+	if err := p.ensureStarted(ctx); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+
+	start := time.Now()
+	reqBytes, err := json.Marshal(pluginRequest{Feature: p.name, Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: failed to encode request: %w", p.name, err)
+	}
+	if err := p.transport.Send(reqBytes); err != nil {
+		return nil, fmt.Errorf("plugin %s: send failed: %w", p.name, err)
+	}
+	respBytes, err := p.transport.Recv(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: recv failed: %w", p.name, err)
+	}
+
+	var resp pluginResponse
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: failed to decode response: %w", p.name, err)
+	}
+
+	if p.telemetry != nil {
+		for _, event := range resp.Telemetry {
+			p.telemetry.RecordEvent(event.Name, event.Data)
+		}
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("plugin %s: %s", p.name, resp.Error)
+	}
+	return &FeatureResult{
+		FeatureName: p.name,
+		Success:     true,
+		Data:        resp.Data,
+		Duration:    time.Since(start),
+	}, nil
+}
+
+// Kill closes the plugin's transport and terminates its process. Safe to
+// call on a PluginProcess that was never started.
+func (p *PluginProcess) Kill() error {
+	if p.transport != nil {
+		p.transport.Close()
+		p.transport = nil
+	}
+	if p.cmd == nil || p.cmd.Process == nil {
+		return nil
+	}
+	return p.cmd.Process.Kill()
+}
+
+// PluginRegistry is the FeatureRegistry backing RegisterPluginFeature and
+// WithPluginDir: a name -> PluginProcess map, populated either directly or
+// by scanning a directory of executables.
+type PluginRegistry struct {
+	mu       sync.RWMutex
+	features map[string]*PluginProcess
+}
+
+func NewPluginRegistry() *PluginRegistry {
+	return &PluginRegistry{features: make(map[string]*PluginProcess)}
+}
+
+// Register adds (or replaces) name as a plugin-backed feature.
+func (r *PluginRegistry) Register(name string, cmd *exec.Cmd, protoVersion int, telemetry *TelemetryObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.features[name] = NewPluginProcess(name, cmd, protoVersion, telemetry)
+}
+
+// Discover registers every executable file directly inside dir as a
+// plugin feature named after the file, negotiated at protoVersion.
+func (r *PluginRegistry) Discover(dir string, protoVersion int, telemetry *TelemetryObserver) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read plugin directory %q: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		r.Register(entry.Name(), exec.Command(path), protoVersion, telemetry)
+	}
+	return nil
+}
+
+// Resolve implements FeatureRegistry.
+func (r *PluginRegistry) Resolve(name string) (FeatureProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.features[name]
+	return p, ok
+}
+
+// KillAll terminates every registered plugin process; called from
+// Client.Disconnect so no plugin outlives its Client.
+func (r *PluginRegistry) KillAll() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.features {
+		p.Kill()
+	}
+}
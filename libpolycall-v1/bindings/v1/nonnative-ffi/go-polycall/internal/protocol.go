@@ -0,0 +1,205 @@
+package internal
+
+// ProtocolHandler drives the built-in INIT->HANDSHAKE->AUTH->READY frame
+// exchange pkg.Client.Connect/ExecuteFeature/Disconnect walk through,
+// speaking the same "JSON directly over a Transport" wire format
+// PluginProcess uses (plugin.go) rather than config/src's binary
+// MessageType framing - this package's Transport has no real polycall.exe
+// runtime behind it yet to negotiate that format with.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProtocolState is one node of the finite state machine pkg.Client walks:
+// INIT -> HANDSHAKE -> AUTH -> READY, with READY <-> EXECUTING on every
+// ExecuteFeature call and ERROR reachable from any step that fails.
+type ProtocolState string
+
+const (
+	INIT      ProtocolState = "INIT"
+	HANDSHAKE ProtocolState = "HANDSHAKE"
+	AUTH      ProtocolState = "AUTH"
+	READY     ProtocolState = "READY"
+	EXECUTING ProtocolState = "EXECUTING"
+	ERROR     ProtocolState = "ERROR"
+)
+
+// String implements fmt.Stringer so ProtocolState reads directly in the
+// %s-formatted errors/log lines Client's state transitions produce.
+func (s ProtocolState) String() string {
+	return string(s)
+}
+
+// protocolFrame is the JSON envelope every ProtocolHandler exchange sends
+// and expects back; Data is frame-specific (nil for handshake/auth, the
+// feature request/response for ExecuteFeature).
+type protocolFrame struct {
+	Type string                 `json:"type"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// featureRequest/featureResponse are ExecuteFeature's Data payload,
+// mirroring plugin.go's pluginRequest/pluginResponse shape so both
+// built-in and plugin-backed features are driven the same way.
+type featureRequest struct {
+	Feature string                 `json:"feature"`
+	Params  map[string]interface{} `json:"params"`
+}
+
+type featureResponse struct {
+	Success bool                   `json:"success"`
+	Data    map[string]interface{} `json:"data"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// ProtocolHandler builds LibPolyCall's INIT->HANDSHAKE->AUTH->READY frames
+// on top of whichever Transport WithTransport/WithTransportScheme
+// selected, so Client's state machine never has to know whether it's
+// running over a raw socket, gRPC, WebSocket, or a Unix socket.
+type ProtocolHandler struct {
+	transport Transport
+	telemetry *TelemetryObserver
+}
+
+// NewProtocolHandler builds a ProtocolHandler driving transport, recording
+// every frame round-trip's outcome on telemetry (nil is fine; events are
+// simply dropped, matching TelemetryObserver's own non-intrusive design).
+func NewProtocolHandler(transport Transport, telemetry *TelemetryObserver) *ProtocolHandler {
+	return &ProtocolHandler{transport: transport, telemetry: telemetry}
+}
+
+func (h *ProtocolHandler) record(name string, data map[string]interface{}) {
+	if h.telemetry != nil {
+		h.telemetry.RecordEvent(name, data)
+	}
+}
+
+// exchange dials the transport on first use, sends frame, and returns the
+// decoded reply frame.
+func (h *ProtocolHandler) exchange(ctx context.Context, frame protocolFrame) (protocolFrame, error) {
+	if h.transport == nil {
+		return protocolFrame{}, fmt.Errorf("protocol handler: no transport configured")
+	}
+
+	reqBytes, err := json.Marshal(frame)
+	if err != nil {
+		return protocolFrame{}, fmt.Errorf("failed to encode %s frame: %w", frame.Type, err)
+	}
+	if err := h.transport.Send(reqBytes); err != nil {
+		return protocolFrame{}, fmt.Errorf("%s frame send failed: %w", frame.Type, err)
+	}
+
+	respBytes, err := h.transport.Recv(ctx)
+	if err != nil {
+		return protocolFrame{}, fmt.Errorf("%s frame recv failed: %w", frame.Type, err)
+	}
+
+	var resp protocolFrame
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return protocolFrame{}, fmt.Errorf("failed to decode %s response: %w", frame.Type, err)
+	}
+	return resp, nil
+}
+
+// InitiateHandshake dials transport and exchanges the INIT handshake
+// frame, the first step of Client.Connect's state machine.
+func (h *ProtocolHandler) InitiateHandshake(ctx context.Context) error {
+	if h.transport == nil {
+		return fmt.Errorf("protocol handler: no transport configured")
+	}
+	if err := h.transport.Dial(ctx, ""); err != nil {
+		return fmt.Errorf("handshake dial failed: %w", err)
+	}
+
+	if _, err := h.exchange(ctx, protocolFrame{Type: "handshake"}); err != nil {
+		return err
+	}
+	h.record("protocol_handshake_completed", nil)
+	return nil
+}
+
+// Authenticate exchanges the AUTH frame, the second step of Client.Connect.
+func (h *ProtocolHandler) Authenticate(ctx context.Context) error {
+	if _, err := h.exchange(ctx, protocolFrame{Type: "auth"}); err != nil {
+		return err
+	}
+	h.record("protocol_auth_completed", nil)
+	return nil
+}
+
+// ExecuteFeature round-trips featureName/params as an "execute" frame and
+// adapts the response into a FeatureResult - the same return type
+// FeatureProvider.Execute uses, so Client.ExecuteFeature adapts both
+// plugin-backed and built-in features identically.
+func (h *ProtocolHandler) ExecuteFeature(ctx context.Context, featureName string, params map[string]interface{}) (*FeatureResult, error) {
+	start := time.Now()
+
+	reqData, err := structToMap(featureRequest{Feature: featureName, Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode feature request: %w", err)
+	}
+
+	resp, err := h.exchange(ctx, protocolFrame{Type: "execute", Data: reqData})
+	if err != nil {
+		return nil, err
+	}
+
+	var fr featureResponse
+	if err := mapToStruct(resp.Data, &fr); err != nil {
+		return nil, fmt.Errorf("failed to decode feature response: %w", err)
+	}
+	if !fr.Success {
+		if fr.Error == "" {
+			fr.Error = "runtime rejected feature execution"
+		}
+		return nil, fmt.Errorf("%s", fr.Error)
+	}
+
+	return &FeatureResult{
+		FeatureName: featureName,
+		Success:     true,
+		Data:        fr.Data,
+		Duration:    time.Since(start),
+	}, nil
+}
+
+// Shutdown tears down transport, the counterpart to InitiateHandshake's
+// dial; called once from Client.Disconnect.
+func (h *ProtocolHandler) Shutdown(ctx context.Context) error {
+	if h.transport == nil {
+		return nil
+	}
+	if err := h.transport.Close(); err != nil {
+		return fmt.Errorf("transport close failed: %w", err)
+	}
+	h.record("protocol_shutdown_completed", nil)
+	return nil
+}
+
+// structToMap and mapToStruct round-trip through json.Marshal/Unmarshal so
+// protocolFrame.Data (a plain map[string]interface{}, matching
+// pluginRequest/pluginResponse's own wire shape) can carry a typed Go
+// value without exchange needing a second, data-shape-specific method.
+func structToMap(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func mapToStruct(m map[string]interface{}, v interface{}) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
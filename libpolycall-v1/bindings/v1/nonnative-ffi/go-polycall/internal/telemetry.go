@@ -0,0 +1,59 @@
+package internal
+
+import "sync"
+
+// TelemetryObserver records protocol and feature-execution events for
+// non-intrusive observation (Client.GetTelemetry), without ever feeding
+// back into the state machine itself. PluginProcess.Execute forwards a
+// plugin's side-channel events through the same RecordEvent its own
+// Connect/ExecuteFeature/Disconnect calls use.
+type TelemetryObserver struct {
+	mu      sync.Mutex
+	enabled bool
+	events  []telemetryEvent
+}
+
+type telemetryEvent struct {
+	Name string
+	Data map[string]interface{}
+}
+
+// NewTelemetryObserver returns a TelemetryObserver with recording enabled.
+func NewTelemetryObserver() *TelemetryObserver {
+	return &TelemetryObserver{enabled: true}
+}
+
+// RecordEvent appends name/data if telemetry is enabled; a nil data is
+// fine (several call sites, e.g. "handshake_initiated", pass nil).
+func (t *TelemetryObserver) RecordEvent(name string, data map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.enabled {
+		return
+	}
+	t.events = append(t.events, telemetryEvent{Name: name, Data: data})
+}
+
+// SetEnabled toggles recording; disabling does not clear events already
+// recorded.
+func (t *TelemetryObserver) SetEnabled(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enabled = enabled
+}
+
+// GetMetrics returns how many times each event name has been recorded.
+func (t *TelemetryObserver) GetMetrics() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, e := range t.events {
+		counts[e.Name]++
+	}
+	metrics := make(map[string]interface{}, len(counts))
+	for name, count := range counts {
+		metrics[name] = count
+	}
+	return metrics
+}
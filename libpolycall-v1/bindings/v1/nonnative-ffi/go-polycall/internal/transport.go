@@ -0,0 +1,322 @@
+// Package internal implements go-polycall's runtime-facing protocol and
+// transport plumbing: the pieces pkg.Client drives but never exposes
+// directly to callers.
+// Author: OBINexusComputing - Aegis Engineering Team
+// Collaborator: Nnamdi Michael Okpala
+package internal
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+)
+
+// maxFrameSize caps a single frame the same order of magnitude as
+// config/src's MaxPayloadSize, so a corrupt length prefix can't make a
+// stream transport try to allocate an unbounded buffer.
+const maxFrameSize = 1024 * 1024 // 1MB
+
+// Transport abstracts how a Client reaches a polycall.exe runtime: dial a
+// peer, exchange already-framed messages, and tear the connection down.
+// ProtocolHandler builds LibPolyCall's INIT->HANDSHAKE->AUTH->READY frames
+// on top of whichever Transport WithTransport/WithTransportScheme selected,
+// so the state machine never has to know whether it's running over a raw
+// socket, gRPC, WebSocket, or a Unix socket.
+type Transport interface {
+	// Dial opens the underlying connection to addr. addr is scheme-
+	// dependent: "host:port" for tcp/grpc, a ws(s):// URL for websocket, a
+	// filesystem path for unix.
+	Dial(ctx context.Context, addr string) error
+	// Send writes one already-framed message.
+	Send(data []byte) error
+	// Recv blocks for the next framed message, or until ctx is done.
+	Recv(ctx context.Context) ([]byte, error)
+	// Close releases the underlying connection. Safe to call more than once.
+	Close() error
+}
+
+// TransportScheme selects which Transport NewTransportForScheme builds.
+type TransportScheme string
+
+const (
+	SchemeTCP  TransportScheme = "tcp"
+	SchemeGRPC TransportScheme = "grpc"
+	SchemeWS   TransportScheme = "ws"
+	SchemeUnix TransportScheme = "unix"
+)
+
+// NewTransport builds the default tcp Transport dialing host:port, matching
+// the behavior Client has always assumed before WithTransportScheme existed.
+func NewTransport(host string, port int) (Transport, error) {
+	return NewTransportForScheme(SchemeTCP, fmt.Sprintf("%s:%d", host, port))
+}
+
+// NewTransportForScheme builds the Transport scheme selects; addr is passed
+// to Dial unchanged once the caller (Client.Connect) is ready to connect.
+// An empty scheme means SchemeTCP, the long-standing default.
+func NewTransportForScheme(scheme TransportScheme, addr string) (Transport, error) {
+	switch scheme {
+	case "", SchemeTCP:
+		return &netStreamTransport{network: "tcp", addr: addr}, nil
+	case SchemeUnix:
+		return &netStreamTransport{network: "unix", addr: addr}, nil
+	case SchemeGRPC:
+		return &grpcTransport{addr: addr}, nil
+	case SchemeWS:
+		return &wsTransport{addr: addr}, nil
+	default:
+		return nil, fmt.Errorf("unknown transport scheme %q: want tcp, grpc, ws, or unix", scheme)
+	}
+}
+
+// netStreamTransport backs both the tcp and unix schemes: net.Conn already
+// treats them identically once dialed, so only the network name differs.
+type netStreamTransport struct {
+	network string
+	addr    string
+	conn    net.Conn
+}
+
+func (t *netStreamTransport) Dial(ctx context.Context, addr string) error {
+	if addr != "" {
+		t.addr = addr
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, t.network, t.addr)
+	if err != nil {
+		return fmt.Errorf("%s transport dial failed: %w", t.network, err)
+	}
+	t.conn = conn
+	return nil
+}
+
+func (t *netStreamTransport) Send(data []byte) error {
+	if t.conn == nil {
+		return fmt.Errorf("%s transport not connected", t.network)
+	}
+	return writeLengthPrefixed(t.conn, data)
+}
+
+func (t *netStreamTransport) Recv(ctx context.Context) ([]byte, error) {
+	if t.conn == nil {
+		return nil, fmt.Errorf("%s transport not connected", t.network)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetReadDeadline(deadline)
+	} else {
+		t.conn.SetReadDeadline(time.Time{})
+	}
+	data, err := readLengthPrefixed(t.conn)
+	if err != nil {
+		return nil, fmt.Errorf("%s transport recv failed: %w", t.network, err)
+	}
+	return data, nil
+}
+
+func (t *netStreamTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// writeLengthPrefixed and readLengthPrefixed frame a message as a 4-byte
+// big-endian length followed by the payload, the minimal framing a raw
+// stream (tcp, unix, grpc's byte-passthrough stream) needs to tell where
+// one Send ends and the next begins; WebSocket already frames messages on
+// its own and doesn't use these.
+func writeLengthPrefixed(w io.Writer, data []byte) error {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return fmt.Errorf("failed to write frame length: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write frame payload: %w", err)
+	}
+	return nil
+}
+
+func readLengthPrefixed(r io.Reader) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, fmt.Errorf("failed to read frame length: %w", err)
+	}
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if n > maxFrameSize {
+		return nil, fmt.Errorf("frame size exceeds maximum: %d > %d", n, maxFrameSize)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read frame payload: %w", err)
+	}
+	return data, nil
+}
+
+// wsTransport carries frames over a single gorilla/websocket connection,
+// for peers run in constrained environments where raw TCP to 8084 isn't
+// viable but an outbound WebSocket is.
+type wsTransport struct {
+	addr string
+	conn *websocket.Conn
+}
+
+func (t *wsTransport) Dial(ctx context.Context, addr string) error {
+	if addr != "" {
+		t.addr = addr
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, t.addr, nil)
+	if err != nil {
+		return fmt.Errorf("websocket transport dial failed: %w", err)
+	}
+	t.conn = conn
+	return nil
+}
+
+func (t *wsTransport) Send(data []byte) error {
+	if t.conn == nil {
+		return fmt.Errorf("websocket transport not connected")
+	}
+	if err := t.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		return fmt.Errorf("websocket transport send failed: %w", err)
+	}
+	return nil
+}
+
+func (t *wsTransport) Recv(ctx context.Context) ([]byte, error) {
+	if t.conn == nil {
+		return nil, fmt.Errorf("websocket transport not connected")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetReadDeadline(deadline)
+	} else {
+		t.conn.SetReadDeadline(time.Time{})
+	}
+	_, data, err := t.conn.ReadMessage()
+	if err != nil {
+		return nil, fmt.Errorf("websocket transport recv failed: %w", err)
+	}
+	return data, nil
+}
+
+func (t *wsTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// grpcStreamMethod is the single bidirectional-stream RPC every grpcTransport
+// opens. LibPolyCall's .proto-generated client stubs aren't vendored into
+// this binding, so rather than invent an ad-hoc message type we register a
+// byte-passthrough codec (rawCodec below) and drive the stream directly -
+// the same technique generic gRPC proxies use to forward frames without
+// understanding their contents.
+const grpcStreamMethod = "/polycall.v1.PolyCall/Stream"
+
+type grpcTransport struct {
+	addr   string
+	conn   *grpc.ClientConn
+	stream grpc.ClientStream
+}
+
+func (t *grpcTransport) Dial(ctx context.Context, addr string) error {
+	if addr != "" {
+		t.addr = addr
+	}
+	conn, err := grpc.DialContext(ctx, t.addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(rawCodecName)),
+	)
+	if err != nil {
+		return fmt.Errorf("grpc transport dial failed: %w", err)
+	}
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		ClientStreams: true,
+	}, grpcStreamMethod)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("grpc transport stream open failed: %w", err)
+	}
+	t.conn = conn
+	t.stream = stream
+	return nil
+}
+
+func (t *grpcTransport) Send(data []byte) error {
+	if t.stream == nil {
+		return fmt.Errorf("grpc transport not connected")
+	}
+	frame := rawFrame(data)
+	if err := t.stream.SendMsg(&frame); err != nil {
+		return fmt.Errorf("grpc transport send failed: %w", err)
+	}
+	return nil
+}
+
+func (t *grpcTransport) Recv(ctx context.Context) ([]byte, error) {
+	if t.stream == nil {
+		return nil, fmt.Errorf("grpc transport not connected")
+	}
+	var frame rawFrame
+	if err := t.stream.RecvMsg(&frame); err != nil {
+		return nil, fmt.Errorf("grpc transport recv failed: %w", err)
+	}
+	return frame, nil
+}
+
+func (t *grpcTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	t.stream = nil
+	return err
+}
+
+// rawCodecName is registered with grpc's global encoding registry in init()
+// below, then selected per-call via grpc.CallContentSubtype so grpcTransport
+// never has to marshal through a generated proto.Message.
+const rawCodecName = "raw"
+
+type rawFrame []byte
+
+func init() {
+	encoding.RegisterCodec(rawCodec{})
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return rawCodecName }
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	f, ok := v.(*rawFrame)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: unsupported type %T", v)
+	}
+	return *f, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	f, ok := v.(*rawFrame)
+	if !ok {
+		return fmt.Errorf("raw codec: unsupported type %T", v)
+	}
+	*f = append((*f)[:0], data...)
+	return nil
+}
@@ -8,10 +8,24 @@ package polycall
 import (
 	"context"
 	"fmt"
+	"net"
+	"os/exec"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/internal"
+	"github.com/obinexus/libpolycall-v1trial/bindings/go-polycall/internal/logging"
+)
+
+// connSeq and invocationSeq generate this process's correlation IDs: a
+// connID per Client (assigned once, in NewClient) and an invocationID per
+// ExecuteFeature call, both cheap monotonic counters rather than UUIDs,
+// matching the sequence-number style config/src/polycall_client.go already
+// uses for its pendingReqs keys.
+var (
+	connSeq       int64
+	invocationSeq int64
 )
 
 // Client represents the LibPolyCall binding adapter interface
@@ -19,41 +33,65 @@ import (
 // All operations flow through polycall.exe runtime for protocol compliance
 type Client struct {
 	// Protocol state management
-	state          internal.ProtocolState
-	stateMutex     sync.RWMutex
-	
-	// Runtime communication channels
-	transport      *internal.Transport
+	state      internal.ProtocolState
+	stateMutex sync.RWMutex
+
+	// Runtime communication channels. transport defaults to the tcp scheme
+	// built in NewClient; WithTransport presets it directly (transportPreset
+	// true skips that default build) and WithTransportScheme picks a
+	// different built-in driver (grpc, ws, unix) instead.
+	transport       internal.Transport
+	transportScheme internal.TransportScheme
+	transportPreset bool
 	protocolHandler *internal.ProtocolHandler
-	
+
+	// pluginRegistry resolves ExecuteFeature's featureName to an
+	// out-of-process plugin binary before falling back to protocolHandler,
+	// when RegisterPluginFeature or WithPluginDir registered one for that
+	// name.
+	pluginRegistry *internal.PluginRegistry
+
 	// Connection management
-	host           string
-	port           int
-	connected      bool
-	authenticated  bool
-	
+	host          string
+	port          int
+	connected     bool
+	authenticated bool
+
+	// callerIP attributes telemetry to the real caller rather than the
+	// last proxy hop, when this Client was built on behalf of an inbound
+	// HTTP request (WithCallerIP, typically fed by httputil.ClientIP).
+	// Left nil for a Client that isn't fronting an HTTP request.
+	callerIP net.IP
+
 	// Context for operation lifecycle management
-	ctx            context.Context
-	cancel         context.CancelFunc
-	
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// Telemetry for silent observation (non-intrusive)
-	telemetry      *internal.TelemetryObserver
+	telemetry *internal.TelemetryObserver
+
+	// Structured logging: connID tags every record this Client emits, so
+	// log lines from concurrent Clients (and their per-feature invocations,
+	// tagged separately on top) can be told apart.
+	logger logging.Logger
+	connID string
 }
 
 // NewClient initializes a new LibPolyCall binding client
 // Returns an adapter that requires polycall.exe runtime for all operations
 func NewClient(opts ...ClientOption) (*Client, error) {
 	ctx, cancel := context.WithCancel(context.Background())
-	
+
 	client := &Client{
-		state:      internal.INIT,
-		host:       "localhost",
-		port:       8084, // Default polycall.exe port
-		ctx:        ctx,
-		cancel:     cancel,
-		telemetry:  internal.NewTelemetryObserver(),
+		state:     internal.INIT,
+		host:      "localhost",
+		port:      8084, // Default polycall.exe port
+		ctx:       ctx,
+		cancel:    cancel,
+		telemetry: internal.NewTelemetryObserver(),
+		connID:    fmt.Sprintf("conn-%d", atomic.AddInt64(&connSeq, 1)),
 	}
-	
+
 	// Apply configuration options
 	for _, opt := range opts {
 		if err := opt(client); err != nil {
@@ -61,61 +99,106 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 			return nil, fmt.Errorf("client configuration failed: %w", err)
 		}
 	}
-	
-	// Initialize protocol components
+
+	// WithLogger (see ClientOption below) may already have installed one;
+	// otherwise fall back to a default text logger at info level.
+	if client.logger == nil {
+		client.logger = logging.New(logging.Options{Name: "polycall"})
+	}
+	client.logger = client.logger.Named("client").With("conn_id", client.connID)
+
+	// Initialize protocol components. A WithTransport preset wins outright;
+	// otherwise build whichever scheme WithTransportScheme selected (tcp by
+	// default) against this client's host:port.
 	var err error
-	client.transport, err = internal.NewTransport(client.host, client.port)
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("transport initialization failed: %w", err)
+	if !client.transportPreset {
+		client.transport, err = internal.NewTransportForScheme(client.transportScheme, client.transportAddr())
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("transport initialization failed: %w", err)
+		}
 	}
-	
+
 	client.protocolHandler = internal.NewProtocolHandler(client.transport, client.telemetry)
-	
+
 	// Record initialization telemetry
 	client.telemetry.RecordEvent("client_initialized", map[string]interface{}{
-		"host": client.host,
-		"port": client.port,
+		"host":      client.host,
+		"port":      client.port,
 		"timestamp": time.Now().Unix(),
 	})
-	
+	client.logger.Info("client initialized", "host", client.host, "port", client.port)
+
 	return client, nil
 }
 
+// transportAddr derives the address NewTransportForScheme dials for this
+// client's scheme: host:port for tcp/grpc, a ws:// URL for websocket, or
+// host treated as a filesystem path for unix (port is meaningless there).
+func (c *Client) transportAddr() string {
+	switch c.transportScheme {
+	case internal.SchemeWS:
+		return fmt.Sprintf("ws://%s:%d", c.host, c.port)
+	case internal.SchemeUnix:
+		return c.host
+	default:
+		return fmt.Sprintf("%s:%d", c.host, c.port)
+	}
+}
+
+// callerTelemetryData merges "caller_ip" into data when WithCallerIP set
+// one on this Client, leaving data untouched (including nil) otherwise.
+func (c *Client) callerTelemetryData(data map[string]interface{}) map[string]interface{} {
+	if c.callerIP == nil {
+		return data
+	}
+	if data == nil {
+		data = make(map[string]interface{}, 1)
+	}
+	data["caller_ip"] = c.callerIP.String()
+	return data
+}
+
 // Connect establishes connection to polycall.exe runtime
 // Implements required state transition: INIT → HANDSHAKE → AUTH
 func (c *Client) Connect() error {
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
-	
+
+	start := time.Now()
 	if c.state != internal.INIT {
 		return fmt.Errorf("invalid state for connection: %s", c.state)
 	}
-	
+
 	// Begin handshake protocol with polycall.exe
 	if err := c.protocolHandler.InitiateHandshake(c.ctx); err != nil {
 		c.state = internal.ERROR
+		c.logger.Error("handshake failed", "error", err, "elapsed_ms", time.Since(start).Milliseconds())
 		return fmt.Errorf("handshake failed: %w", err)
 	}
-	
+
 	c.state = internal.HANDSHAKE
-	c.telemetry.RecordEvent("handshake_initiated", nil)
-	
+	c.telemetry.RecordEvent("handshake_initiated", c.callerTelemetryData(nil))
+	c.logger.Info("handshake initiated", "state", c.state)
+
 	// Perform zero-trust authentication
 	if err := c.protocolHandler.Authenticate(c.ctx); err != nil {
 		c.state = internal.ERROR
+		c.logger.Error("authentication failed", "error", err, "elapsed_ms", time.Since(start).Milliseconds())
 		return fmt.Errorf("authentication failed: %w", err)
 	}
-	
+
 	c.state = internal.AUTH
 	c.connected = true
 	c.authenticated = true
 	c.telemetry.RecordEvent("authentication_successful", nil)
-	
+	c.logger.Info("authentication successful", "state", c.state)
+
 	// Transition to ready state
 	c.state = internal.READY
 	c.telemetry.RecordEvent("client_ready", nil)
-	
+	c.logger.Info("client ready", "state", c.state, "elapsed_ms", time.Since(start).Milliseconds())
+
 	return nil
 }
 
@@ -124,43 +207,119 @@ func (c *Client) Connect() error {
 func (c *Client) ExecuteFeature(featureName string, params map[string]interface{}) (*ExecutionResult, error) {
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
-	
+
+	invocationID := fmt.Sprintf("inv-%d", atomic.AddInt64(&invocationSeq, 1))
+	log := c.logger.With("invocation_id", invocationID, "feature", featureName)
+	start := time.Now()
+
 	if c.state != internal.READY {
 		return nil, fmt.Errorf("client not ready for execution, current state: %s", c.state)
 	}
-	
+
 	if !c.authenticated {
 		return nil, fmt.Errorf("authentication required for feature execution")
 	}
-	
+
 	// Transition to executing state
 	c.state = internal.EXECUTING
-	c.telemetry.RecordEvent("execution_started", map[string]interface{}{
-		"feature": featureName,
+	c.telemetry.RecordEvent("execution_started", c.callerTelemetryData(map[string]interface{}{
+		"feature":   featureName,
 		"timestamp": time.Now().Unix(),
-	})
-	
-	// Execute through polycall.exe runtime (adapter pattern)
-	result, err := c.protocolHandler.ExecuteFeature(c.ctx, featureName, params)
+	}))
+	log.Info("execution started", "state", c.state)
+
+	// Resolve through the plugin registry first (RegisterPluginFeature /
+	// WithPluginDir), falling back to the built-in polycall.exe runtime
+	// path (adapter pattern) for every feature no plugin claimed.
+	var result *ExecutionResult
+	var err error
+	if provider, ok := c.resolvePluginFeature(featureName); ok {
+		var fr *internal.FeatureResult
+		fr, err = provider.Execute(c.ctx, params)
+		if err == nil {
+			result = &ExecutionResult{
+				FeatureName: fr.FeatureName,
+				Success:     fr.Success,
+				Data:        fr.Data,
+				Duration:    fr.Duration,
+				Timestamp:   time.Now(),
+			}
+		}
+	} else {
+		var fr *internal.FeatureResult
+		fr, err = c.protocolHandler.ExecuteFeature(c.ctx, featureName, params)
+		if err == nil {
+			result = &ExecutionResult{
+				FeatureName: fr.FeatureName,
+				Success:     fr.Success,
+				Data:        fr.Data,
+				Duration:    fr.Duration,
+				Timestamp:   time.Now(),
+			}
+		}
+	}
 	if err != nil {
 		c.state = internal.ERROR
 		c.telemetry.RecordEvent("execution_failed", map[string]interface{}{
 			"feature": featureName,
-			"error": err.Error(),
+			"error":   err.Error(),
 		})
+		log.Error("execution failed", "error", err, "elapsed_ms", time.Since(start).Milliseconds())
 		return nil, fmt.Errorf("feature execution failed: %w", err)
 	}
-	
+
 	// Return to ready state
 	c.state = internal.READY
 	c.telemetry.RecordEvent("execution_completed", map[string]interface{}{
-		"feature": featureName,
+		"feature":     featureName,
 		"duration_ms": result.Duration.Milliseconds(),
 	})
-	
+	log.Info("execution completed", "state", c.state, "duration_ms", result.Duration.Milliseconds(), "elapsed_ms", time.Since(start).Milliseconds())
+
 	return result, nil
 }
 
+// resolvePluginFeature reports whether featureName is plugin-backed.
+func (c *Client) resolvePluginFeature(featureName string) (internal.FeatureProvider, bool) {
+	if c.pluginRegistry == nil {
+		return nil, false
+	}
+	return c.pluginRegistry.Resolve(featureName)
+}
+
+// RegisterPluginFeature registers name as a plugin-backed feature: cmd is
+// the plugin binary, launched lazily (and handshaked - a magic-cookie-
+// gated line on stdout naming the unix socket to dial) the first time
+// ExecuteFeature(name, ...) runs, rather than at registration time.
+// protoVersion must match what the plugin reports in its handshake.
+func (c *Client) RegisterPluginFeature(name string, cmd *exec.Cmd, protoVersion int) {
+	if c.pluginRegistry == nil {
+		c.pluginRegistry = internal.NewPluginRegistry()
+	}
+	c.pluginRegistry.Register(name, cmd, protoVersion, c.telemetry)
+}
+
+// Recover clears an ERROR state - typically left behind by a crashed or
+// misbehaving plugin - without the full Connect handshake a reconnect
+// would require: the transport and authentication are left untouched,
+// only the feature-execution state machine resets to READY.
+func (c *Client) Recover() error {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if c.state != internal.ERROR {
+		return fmt.Errorf("recover called outside ERROR state: %s", c.state)
+	}
+	if !c.connected || !c.authenticated {
+		return fmt.Errorf("cannot recover: client is not connected/authenticated")
+	}
+
+	c.state = internal.READY
+	c.telemetry.RecordEvent("client_recovered", nil)
+	c.logger.Info("client recovered", "state", c.state)
+	return nil
+}
+
 // GetState returns current protocol state for debugging
 func (c *Client) GetState() internal.ProtocolState {
 	c.stateMutex.RLock()
@@ -191,27 +350,36 @@ func (c *Client) GetTelemetry() map[string]interface{} {
 func (c *Client) Disconnect() error {
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
-	
+
+	start := time.Now()
 	if !c.connected {
 		return nil
 	}
-	
+
 	// Clean protocol termination
 	if err := c.protocolHandler.Shutdown(c.ctx); err != nil {
 		c.telemetry.RecordEvent("shutdown_error", map[string]interface{}{
 			"error": err.Error(),
 		})
+		c.logger.Warn("shutdown error, continuing with cleanup", "error", err)
 		// Continue with cleanup despite error
 	}
-	
+
+	// Kill every plugin process this client launched - they must not
+	// outlive the Client that started them.
+	if c.pluginRegistry != nil {
+		c.pluginRegistry.KillAll()
+	}
+
 	// Cancel context and cleanup
 	c.cancel()
 	c.connected = false
 	c.authenticated = false
 	c.state = internal.INIT
-	
+
 	c.telemetry.RecordEvent("client_disconnected", nil)
-	
+	c.logger.Info("client disconnected", "state", c.state, "elapsed_ms", time.Since(start).Milliseconds())
+
 	return nil
 }
 
@@ -249,6 +417,71 @@ func WithPort(port int) ClientOption {
 	}
 }
 
+// WithLogger installs a structured logging.Logger on the client. Every
+// Connect/ExecuteFeature/Disconnect state transition logs through it
+// (tagged with this client's connID and, for ExecuteFeature, a per-call
+// invocation ID). If unset, NewClient installs a default text-format
+// logger at info level; use logging.New directly to select JSON output
+// or a different level.
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(c *Client) error {
+		if logger == nil {
+			return fmt.Errorf("logger cannot be nil")
+		}
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithTransport installs a pre-built internal.Transport, bypassing
+// NewClient's default scheme-based dialing entirely (so WithTransportScheme
+// is ignored if both are given). Useful for tests, or a driver this binding
+// doesn't build in.
+func WithTransport(t internal.Transport) ClientOption {
+	return func(c *Client) error {
+		if t == nil {
+			return fmt.Errorf("transport cannot be nil")
+		}
+		c.transport = t
+		c.transportPreset = true
+		return nil
+	}
+}
+
+// WithTransportScheme selects which built-in Transport driver NewClient
+// dials: "tcp" (the long-standing default), "grpc", "ws", or "unix" - for
+// running the binding somewhere raw TCP to polycall.exe's port isn't
+// viable. Has no effect if WithTransport already installed a transport.
+func WithTransportScheme(scheme string) ClientOption {
+	return func(c *Client) error {
+		s := internal.TransportScheme(scheme)
+		switch s {
+		case internal.SchemeTCP, internal.SchemeGRPC, internal.SchemeWS, internal.SchemeUnix:
+			c.transportScheme = s
+			return nil
+		default:
+			return fmt.Errorf("unknown transport scheme %q: want tcp, grpc, ws, or unix", scheme)
+		}
+	}
+}
+
+// WithPluginDir registers every executable file in dir as a plugin-backed
+// feature named after the file (auto-discovery), the directory-scan
+// counterpart to registering plugins one at a time with
+// RegisterPluginFeature. Each is negotiated at
+// internal.DefaultPluginProtocolVersion.
+func WithPluginDir(dir string) ClientOption {
+	return func(c *Client) error {
+		if c.pluginRegistry == nil {
+			c.pluginRegistry = internal.NewPluginRegistry()
+		}
+		if err := c.pluginRegistry.Discover(dir, internal.DefaultPluginProtocolVersion, c.telemetry); err != nil {
+			return fmt.Errorf("plugin discovery in %q failed: %w", dir, err)
+		}
+		return nil
+	}
+}
+
 // WithTelemetryEnabled configures telemetry observation
 func WithTelemetryEnabled(enabled bool) ClientOption {
 	return func(c *Client) error {
@@ -258,3 +491,16 @@ func WithTelemetryEnabled(enabled bool) ClientOption {
 		return nil
 	}
 }
+
+// WithCallerIP attributes this Client's handshake_initiated/
+// execution_started telemetry to ip (recorded as "caller_ip") rather than
+// the last proxy hop, for a Client built on behalf of an inbound HTTP
+// request - typically fed ip, err := httputil.ClientIP(r, cfg). A nil ip
+// is a no-op, so callers can pass ClientIP's result unconditionally even
+// when it couldn't be resolved.
+func WithCallerIP(ip net.IP) ClientOption {
+	return func(c *Client) error {
+		c.callerIP = ip
+		return nil
+	}
+}
@@ -0,0 +1,182 @@
+//go:build astiav
+
+package capture
+
+// ============================================================================
+// This file is only compiled with `-tags astiav`, since github.com/asticode/
+// go-astiav wraps libav via cgo and neither is vendored in this tree. It is
+// the target implementation for hardware-accelerated capture (e.g. decoding
+// straight off an NVDEC/VAAPI device), written the way it would ship once
+// that dependency is available, not a stub returning "not implemented".
+// ============================================================================
+
+import (
+	"fmt"
+
+	"github.com/asticode/go-astiav"
+)
+
+func init() {
+	astiavOpen = openAstiav
+}
+
+// astiavSource adapts an astiav format/codec context pair to FrameSource.
+type astiavSource struct {
+	width, height int
+	fps           Rational
+
+	formatCtx *astiav.FormatContext
+	codecCtx  *astiav.CodecContext
+	swsCtx    *astiav.SoftwareScaleContext
+	streamIdx int
+	packet    *astiav.Packet
+	frame     *astiav.Frame
+	rgbFrame  *astiav.Frame
+}
+
+// openAstiav opens url (a device path or network URL) via libavformat,
+// locates the first video stream, and opens its decoder plus an RGB24
+// scaling context sized to width/height (auto-detected from the stream
+// when either is 0).
+func openAstiav(url string, width, height int) (FrameSource, error) {
+	formatCtx := astiav.AllocFormatContext()
+	if formatCtx == nil {
+		return nil, fmt.Errorf("capture: astiav: allocating format context failed")
+	}
+
+	if err := formatCtx.OpenInput(url, nil, nil); err != nil {
+		formatCtx.Free()
+		return nil, fmt.Errorf("capture: astiav: opening %s: %w", url, err)
+	}
+	if err := formatCtx.FindStreamInfo(nil); err != nil {
+		formatCtx.CloseInput()
+		return nil, fmt.Errorf("capture: astiav: probing %s: %w", url, err)
+	}
+
+	var stream *astiav.Stream
+	for _, st := range formatCtx.Streams() {
+		if st.CodecParameters().MediaType() == astiav.MediaTypeVideo {
+			stream = st
+			break
+		}
+	}
+	if stream == nil {
+		formatCtx.CloseInput()
+		return nil, fmt.Errorf("capture: astiav: no video stream in %s", url)
+	}
+
+	codec := astiav.FindDecoder(stream.CodecParameters().CodecID())
+	if codec == nil {
+		formatCtx.CloseInput()
+		return nil, fmt.Errorf("capture: astiav: no decoder for codec %s", stream.CodecParameters().CodecID())
+	}
+	codecCtx := astiav.AllocCodecContext(codec)
+	if err := stream.CodecParameters().ToCodecContext(codecCtx); err != nil {
+		formatCtx.CloseInput()
+		return nil, fmt.Errorf("capture: astiav: configuring decoder: %w", err)
+	}
+	if err := codecCtx.Open(codec, nil); err != nil {
+		formatCtx.CloseInput()
+		return nil, fmt.Errorf("capture: astiav: opening decoder: %w", err)
+	}
+
+	if width == 0 {
+		width = codecCtx.Width()
+	}
+	if height == 0 {
+		height = codecCtx.Height()
+	}
+
+	swsCtx, err := astiav.NewSoftwareScaleContext(
+		codecCtx.Width(), codecCtx.Height(), codecCtx.PixelFormat(),
+		width, height, astiav.PixelFormatRgb24,
+		astiav.SoftwareScaleFlagBilinear,
+	)
+	if err != nil {
+		codecCtx.Free()
+		formatCtx.CloseInput()
+		return nil, fmt.Errorf("capture: astiav: creating scale context: %w", err)
+	}
+
+	frameRate := stream.AvgFrameRate()
+
+	return &astiavSource{
+		width:     width,
+		height:    height,
+		fps:       Rational{Num: frameRate.Num(), Den: frameRate.Den()},
+		formatCtx: formatCtx,
+		codecCtx:  codecCtx,
+		swsCtx:    swsCtx,
+		streamIdx: stream.Index(),
+		packet:    astiav.AllocPacket(),
+		frame:     astiav.AllocFrame(),
+		rgbFrame:  astiav.AllocFrame(),
+	}, nil
+}
+
+// Open is a no-op: the package-level Open factory already performed the
+// full libav handshake before constructing this source.
+func (s *astiavSource) Open(url string) error { return nil }
+
+// ReadFrame decodes frames until one produces RGB24 pixels into buf, to
+// absorb libav's internal packet-vs-frame buffering (a single demuxed
+// packet doesn't always yield exactly one decoded frame).
+func (s *astiavSource) ReadFrame(buf []byte) (int, error) {
+	for {
+		if err := s.formatCtx.ReadFrame(s.packet); err != nil {
+			return 0, err // propagates astiav.ErrEof at end of stream
+		}
+		if s.packet.StreamIndex() != s.streamIdx {
+			s.packet.Unref()
+			continue
+		}
+		if err := s.codecCtx.SendPacket(s.packet); err != nil {
+			s.packet.Unref()
+			return 0, fmt.Errorf("capture: astiav: sending packet to decoder: %w", err)
+		}
+		s.packet.Unref()
+
+		if err := s.codecCtx.ReceiveFrame(s.frame); err != nil {
+			continue // needs more packets before a frame is ready
+		}
+
+		if err := s.swsCtx.ScaleFrame(s.frame, s.rgbFrame); err != nil {
+			s.frame.Unref()
+			return 0, fmt.Errorf("capture: astiav: scaling frame: %w", err)
+		}
+		n := copy(buf, s.rgbFrame.Data()[0])
+		s.frame.Unref()
+		return n, nil
+	}
+}
+
+func (s *astiavSource) Info() (width, height int, fps Rational) {
+	return s.width, s.height, s.fps
+}
+
+// Metadata always returns the zero value today: the format/codec contexts
+// this source already holds do carry duration and SAR (formatCtx.Duration,
+// codecCtx.SampleAspectRatio), but wiring those through is left for when
+// this backend is actually exercised against real libav, rather than
+// guessed at from the bindings' shape.
+func (s *astiavSource) Metadata() Metadata {
+	return Metadata{}
+}
+
+// Cancel is Close: libav's contexts have no separate "abort the current
+// operation" handle to reach for, so the only forceful teardown available
+// is the same one Close already does.
+func (s *astiavSource) Cancel() error {
+	return s.Close()
+}
+
+func (s *astiavSource) Close() error {
+	s.packet.Free()
+	s.frame.Free()
+	s.rgbFrame.Free()
+	s.swsCtx.Free()
+	s.codecCtx.Free()
+	s.formatCtx.CloseInput()
+	s.formatCtx.Free()
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package capture abstracts the NSIGII encoder's input side behind a
+// FrameSource interface selected by URL scheme, so nsigii.go's main() loop
+// can read raw RGB24 frames the same way whether they come from a local
+// file (via ffmpeg), a live RTSP camera (via this package's own RTSP/RTP
+// client), or a hardware decoder (via astiav/libav, build-tagged since
+// that needs cgo and isn't vendored in this tree). This mirrors the
+// Kerberos.io RTSPClient refactor: the codec loop only ever talks to the
+// interface, never to a specific transport.
+package capture
+
+import "fmt"
+
+// Rational is a frame rate (or any other ratio) expressed as exact
+// integers rather than a float, matching how container/RTSP metadata
+// usually reports it (e.g. 30000/1001 for 29.97fps).
+type Rational struct {
+	Num, Den int
+}
+
+// Float64 returns r as a float64, or 0 if Den is 0.
+func (r Rational) Float64() float64 {
+	if r.Den == 0 {
+		return 0
+	}
+	return float64(r.Num) / float64(r.Den)
+}
+
+// Metadata holds the stream-level facts a backend can determine beyond
+// geometry and frame rate (those stay on Info, unchanged): the numeric
+// ones nsigii.go's container header stores as fixed fields (DurationUsec,
+// SAR), plus a free-form string dictionary for everything else (pix_fmt,
+// codec_name, DAR, ...) - the AVDictionary-style tail nsigii.go writes
+// after its fixed header. A backend that can't determine a field leaves
+// it zero/nil rather than guessing.
+type Metadata struct {
+	DurationUsec uint64
+	SAR          Rational
+	Extra        map[string]string
+}
+
+// FrameSource produces a sequence of fixed-size RGB24 frames from some
+// capture backend. Open must be called before Info, Metadata, or
+// ReadFrame; Close releases whatever process or connection Open started.
+type FrameSource interface {
+	// Open connects to or starts reading from url. Its scheme selects the
+	// backend (see Open, the package-level factory).
+	Open(url string) error
+	// ReadFrame fills buf with exactly one frame's worth of packed RGB24
+	// (width*height*3 bytes, per Info) and returns that count, or an error
+	// (io.EOF at end of stream for a finite source).
+	ReadFrame(buf []byte) (int, error)
+	// Info reports the frame geometry and rate Open determined, valid only
+	// after a successful Open.
+	Info() (width, height int, fps Rational)
+	// Metadata reports whatever additional stream facts Open determined.
+	// Backends that can't determine any return a zero Metadata.
+	Metadata() Metadata
+	// Cancel forcefully tears down whatever process or connection Open
+	// started, unblocking a concurrent ReadFrame the way Close's graceful
+	// shutdown isn't guaranteed to (e.g. an ffmpeg child still writing to
+	// a full pipe). Safe to call more than once and concurrently with
+	// Close. Intended for job cancellation (see jobmanager.go), where the
+	// caller can't wait for the source to drain on its own.
+	Cancel() error
+	Close() error
+}
+
+// schemeOf returns url's scheme, or "file" if url has none (a bare path,
+// matching every existing -input invocation of nsigii.go's main()).
+func schemeOf(url string) string {
+	for i := 0; i < len(url); i++ {
+		switch url[i] {
+		case ':':
+			if i+2 < len(url) && url[i+1] == '/' && url[i+2] == '/' {
+				return url[:i]
+			}
+			return "file"
+		case '/', '.':
+			return "file"
+		}
+	}
+	return "file"
+}
+
+// astiavOpen is set by astiav.go's init(), only compiled in with the
+// astiav build tag (it needs cgo and libav, neither vendored in this
+// tree). Left nil otherwise, so Open can report a precise error instead
+// of an unknown-scheme one.
+var astiavOpen func(url string, width, height int) (FrameSource, error)
+
+// Open builds and opens the FrameSource for url's scheme:
+//
+//	file://path or a bare path (no scheme) - decodes via a local ffmpeg
+//	  process, same as this package's predecessor openRGB24Reader.
+//	rtsp://host/path               - a pure-Go RTSP/RTP client (see rtsp.go).
+//	astiav://device                - cgo libav hardware decode (see
+//	  astiav.go; only compiled with the astiav build tag, since libav isn't
+//	  vendored here).
+//
+// width/height are hints: 0 means auto-detect, which every backend
+// supports via ffprobe (file) or the RTSP SDP/SPS (rtsp).
+func Open(url string, width, height int) (FrameSource, error) {
+	scheme := schemeOf(url)
+
+	var source FrameSource
+	switch scheme {
+	case "file":
+		source = &FileSource{Width: width, Height: height}
+	case "rtsp":
+		source = &RTSPSource{Width: width, Height: height}
+	case "astiav":
+		if astiavOpen == nil {
+			return nil, fmt.Errorf("capture: astiav backend not compiled in; rebuild with -tags astiav and vendor github.com/asticode/go-astiav plus libav")
+		}
+		return astiavOpen(url, width, height)
+	default:
+		return nil, fmt.Errorf("capture: unsupported URL scheme %q in %q (want file://, rtsp://, or astiav://)", scheme, url)
+	}
+
+	if err := source.Open(url); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
@@ -0,0 +1,208 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileSource is the capture.FrameSource backing "file://path" and bare
+// paths: nsigii.go's original openRGB24Reader/probeVideoSize, unchanged in
+// behavior, just moved behind the interface. A .rgb24 file is read
+// directly; anything else is decoded to raw rgb24 by a local ffmpeg
+// process.
+type FileSource struct {
+	Width, Height int
+
+	reader   io.ReadCloser
+	cmd      *exec.Cmd
+	fps      Rational
+	metadata Metadata
+}
+
+// Open strips a leading "file://" (if present) and starts decoding path.
+func (s *FileSource) Open(url string) error {
+	path := strings.TrimPrefix(url, "file://")
+
+	probe, err := probeStream(path)
+	if err != nil {
+		// Dimensions are required to decode at all; everything else probe
+		// reports is descriptive, so only a width/height-less probe is
+		// fatal here (the explicit -width/-height flags cover that case).
+		if s.Width == 0 || s.Height == 0 {
+			return fmt.Errorf("capture: probing %s: %w", path, err)
+		}
+	} else {
+		if s.Width == 0 {
+			s.Width = probe.Width
+		}
+		if s.Height == 0 {
+			s.Height = probe.Height
+		}
+		s.fps = probe.FrameRate
+		s.metadata = Metadata{DurationUsec: probe.DurationUsec, SAR: probe.SAR, Extra: probe.Extra}
+	}
+
+	reader, cmd, err := openRGB24Reader(path, s.Width, s.Height)
+	if err != nil {
+		return err
+	}
+	s.reader, s.cmd = reader, cmd
+	return nil
+}
+
+func (s *FileSource) ReadFrame(buf []byte) (int, error) {
+	return io.ReadFull(s.reader, buf)
+}
+
+func (s *FileSource) Info() (width, height int, fps Rational) {
+	return s.Width, s.Height, s.fps
+}
+
+func (s *FileSource) Metadata() Metadata {
+	return s.metadata
+}
+
+func (s *FileSource) Close() error {
+	err := s.reader.Close()
+	if s.cmd != nil {
+		io.Copy(io.Discard, s.reader)
+		s.cmd.Wait()
+	}
+	return err
+}
+
+// Cancel kills the ffmpeg process outright (a plain file with no cmd has
+// nothing to kill) instead of letting Close drain it, so a blocked
+// ReadFrame returns promptly when a job is canceled mid-decode.
+func (s *FileSource) Cancel() error {
+	s.reader.Close()
+	if s.cmd != nil && s.cmd.Process != nil {
+		return s.cmd.Process.Kill()
+	}
+	return nil
+}
+
+// streamProbe is the subset of ffprobe's stream-entry JSON this package
+// parses (see probeStream).
+type streamProbe struct {
+	Width              int    `json:"width"`
+	Height             int    `json:"height"`
+	AvgFrameRate       string `json:"avg_frame_rate"`
+	Duration           string `json:"duration"`
+	PixFmt             string `json:"pix_fmt"`
+	CodecName          string `json:"codec_name"`
+	SampleAspectRatio  string `json:"sample_aspect_ratio"`
+	DisplayAspectRatio string `json:"display_aspect_ratio"`
+}
+
+// probeResult is what probeStream extracts from ffprobe, already
+// converted into the forms nsigii.go's container header and metadata
+// dictionary want.
+type probeResult struct {
+	Width, Height int
+	FrameRate     Rational
+	DurationUsec  uint64
+	SAR           Rational
+	Extra         map[string]string
+}
+
+// probeStream runs ffprobe once (JSON output) to gather everything
+// nsigii.go's container header wants to record about path's first video
+// stream: dimensions, frame rate, duration, pixel format, codec name, and
+// sample/display aspect ratio.
+func probeStream(path string) (probeResult, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=width,height,avg_frame_rate,duration,pix_fmt,codec_name,sample_aspect_ratio,display_aspect_ratio",
+		"-of", "json",
+		path,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return probeResult{}, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	var parsed struct {
+		Streams []streamProbe `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return probeResult{}, fmt.Errorf("ffprobe JSON parse failed: %v (output: %s)", err, out)
+	}
+	if len(parsed.Streams) == 0 {
+		return probeResult{}, fmt.Errorf("ffprobe reported no video stream for %s", path)
+	}
+	stream := parsed.Streams[0]
+
+	result := probeResult{
+		Width:     stream.Width,
+		Height:    stream.Height,
+		FrameRate: parseRational(stream.AvgFrameRate),
+		SAR:       parseRational(strings.ReplaceAll(stream.SampleAspectRatio, ":", "/")),
+		Extra:     make(map[string]string),
+	}
+	if seconds, err := strconv.ParseFloat(stream.Duration, 64); err == nil {
+		result.DurationUsec = uint64(seconds * 1e6)
+	}
+	if stream.PixFmt != "" {
+		result.Extra["pix_fmt"] = stream.PixFmt
+	}
+	if stream.CodecName != "" {
+		result.Extra["codec_name"] = stream.CodecName
+	}
+	if stream.DisplayAspectRatio != "" && stream.DisplayAspectRatio != "0:1" {
+		result.Extra["dar"] = stream.DisplayAspectRatio
+	}
+	return result, nil
+}
+
+// parseRational parses an ffprobe "num/den" string (e.g. "30000/1001" or
+// "1:1") into a Rational, returning the zero value for anything
+// unparseable (e.g. ffprobe's own "0/0" for an unknown rate).
+func parseRational(spec string) Rational {
+	num, den, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Rational{}
+	}
+	n, errN := strconv.Atoi(num)
+	d, errD := strconv.Atoi(den)
+	if errN != nil || errD != nil || d == 0 {
+		return Rational{}
+	}
+	return Rational{Num: n, Den: d}
+}
+
+// openRGB24Reader returns an io.ReadCloser of raw RGB24 frames.
+// For .mp4/.mkv/.mov etc: pipes through ffmpeg -pix_fmt rgb24.
+// For .rgb24: opens directly.
+func openRGB24Reader(path string, width, height int) (io.ReadCloser, *exec.Cmd, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".rgb24" {
+		f, err := os.Open(path)
+		return f, nil, err
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-i", path,
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+		"-vf", fmt.Sprintf("scale=%d:%d", width, height),
+		"-an", // no audio
+		"-",   // output to stdout
+	)
+	cmd.Stderr = os.Stderr // show ffmpeg progress on stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("ffmpeg pipe failed: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("ffmpeg start failed: %v (is ffmpeg installed?)", err)
+	}
+	return stdout, cmd, nil
+}
@@ -0,0 +1,387 @@
+package capture
+
+// ============================================================================
+// PURE-GO RTSP/H264 CAPTURE
+// A minimal gortsplib-style client: DESCRIBE/SETUP/PLAY over one TCP
+// connection, RTP delivered interleaved on that same connection (RFC 2326
+// §10.12) rather than a separate UDP pair, H264 depacketized (FU-A
+// fragments, STAP-A aggregates, and single NAL units) into an Annex-B
+// elementary stream with SPS/PPS re-inserted before every IDR frame.
+//
+// That Annex-B stream still needs an actual H.264 decoder to become YUV/RGB
+// pixels, and this tree has no vendored software decoder (writing one from
+// scratch is its own multi-thousand-line project, well beyond a protocol
+// client). Rather than fake that step, RTSPSource pipes the Annex-B stream
+// it assembles into a local ffmpeg process for decode only - RTSP/RTP/NAL
+// handling is genuinely done in Go here, not shelled out, which is the part
+// this backend exists to replace; ffmpeg here plays the same role a vendored
+// software H.264 decoder would.
+// ============================================================================
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RTSPSource is the capture.FrameSource backing "rtsp://" URLs.
+type RTSPSource struct {
+	Width, Height int
+
+	conn   net.Conn
+	reader *bufio.Reader
+	cseq   int
+
+	decodeCmd   *exec.Cmd
+	decodeIn    io.WriteCloser
+	decodeOut   io.ReadCloser
+	sps, pps    []byte
+	rtpChannel  byte
+	readTimeout time.Duration
+}
+
+// Open performs the RTSP handshake against url (DESCRIBE, SETUP, PLAY) and
+// starts the ffmpeg decode process its RTP feed goroutine writes into.
+func (s *RTSPSource) Open(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("capture: parsing RTSP URL %s: %w", rawURL, err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host += ":554"
+	}
+
+	conn, err := net.DialTimeout("tcp", host, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("capture: dialing RTSP server %s: %w", host, err)
+	}
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	s.readTimeout = 10 * time.Second
+
+	sdp, err := s.describe(rawURL)
+	if err != nil {
+		s.conn.Close()
+		return err
+	}
+
+	trackURL, err := sdpVideoControlURL(sdp, rawURL)
+	if err != nil {
+		s.conn.Close()
+		return err
+	}
+
+	session, err := s.setup(trackURL)
+	if err != nil {
+		s.conn.Close()
+		return err
+	}
+
+	if err := s.play(rawURL, session); err != nil {
+		s.conn.Close()
+		return err
+	}
+
+	if s.Width == 0 || s.Height == 0 {
+		return fmt.Errorf("capture: rtsp source requires an explicit -width/-height (SDP/SPS dimension parsing isn't implemented); got %dx%d", s.Width, s.Height)
+	}
+
+	return s.startDecoder()
+}
+
+// request sends one RTSP request line plus headers and returns the
+// response's status line, headers, and body.
+func (s *RTSPSource) request(method, target string, headers map[string]string) (status string, hdr map[string]string, body []byte, err error) {
+	s.cseq++
+	var req bytes.Buffer
+	fmt.Fprintf(&req, "%s %s RTSP/1.0\r\n", method, target)
+	fmt.Fprintf(&req, "CSeq: %d\r\n", s.cseq)
+	for k, v := range headers {
+		fmt.Fprintf(&req, "%s: %s\r\n", k, v)
+	}
+	req.WriteString("\r\n")
+
+	s.conn.SetDeadline(time.Now().Add(s.readTimeout))
+	if _, err := s.conn.Write(req.Bytes()); err != nil {
+		return "", nil, nil, fmt.Errorf("capture: sending RTSP %s: %w", method, err)
+	}
+
+	statusLine, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("capture: reading RTSP %s response: %w", method, err)
+	}
+
+	hdr = make(map[string]string)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("capture: reading RTSP %s headers: %w", method, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			hdr[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+		}
+	}
+
+	if lenStr, ok := hdr["content-length"]; ok {
+		n, err := strconv.Atoi(lenStr)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("capture: bad Content-Length in RTSP %s response: %w", method, err)
+		}
+		body = make([]byte, n)
+		if _, err := io.ReadFull(s.reader, body); err != nil {
+			return "", nil, nil, fmt.Errorf("capture: reading RTSP %s body: %w", method, err)
+		}
+	}
+
+	if !strings.Contains(statusLine, "200") {
+		return "", nil, nil, fmt.Errorf("capture: RTSP %s failed: %s", method, strings.TrimSpace(statusLine))
+	}
+	return strings.TrimSpace(statusLine), hdr, body, nil
+}
+
+func (s *RTSPSource) describe(rawURL string) (sdp []byte, err error) {
+	_, _, body, err := s.request("DESCRIBE", rawURL, map[string]string{"Accept": "application/sdp"})
+	return body, err
+}
+
+func (s *RTSPSource) setup(trackURL string) (session string, err error) {
+	// Interleaved channel 0 carries RTP, channel 1 its RTCP, both framed
+	// over the RTSP TCP connection itself (RFC 2326 §10.12) rather than a
+	// separate UDP pair: simpler to NAT-traverse and all this client needs.
+	s.rtpChannel = 0
+	_, hdr, _, err := s.request("SETUP", trackURL, map[string]string{
+		"Transport": "RTP/AVP/TCP;unicast;interleaved=0-1",
+	})
+	if err != nil {
+		return "", err
+	}
+	session, _, _ = strings.Cut(hdr["session"], ";") // drop a trailing ";timeout=..."
+	return session, nil
+}
+
+func (s *RTSPSource) play(rawURL, session string) error {
+	_, _, _, err := s.request("PLAY", rawURL, map[string]string{"Session": session, "Range": "npt=0.000-"})
+	return err
+}
+
+// startDecoder launches the ffmpeg process this source's RTP feed
+// goroutine writes Annex-B H264 into, and begins that goroutine.
+func (s *RTSPSource) startDecoder() error {
+	cmd := exec.Command("ffmpeg",
+		"-f", "h264",
+		"-i", "-",
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+		"-vf", fmt.Sprintf("scale=%d:%d", s.Width, s.Height),
+		"-an",
+		"-",
+	)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("capture: opening ffmpeg decode stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("capture: opening ffmpeg decode stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("capture: starting ffmpeg decoder (is it installed?): %w", err)
+	}
+
+	s.decodeCmd, s.decodeIn, s.decodeOut = cmd, stdin, stdout
+	go s.feedRTP()
+	return nil
+}
+
+// feedRTP reads interleaved RTP packets off the RTSP connection, reduces
+// each H264 payload to Annex-B NAL units via depacketizeH264, and writes
+// them to the decoder. It stops silently once the connection closes (Open
+// callers observe that as ReadFrame returning io.EOF/an error from the
+// now-closed decoder pipe).
+func (s *RTSPSource) feedRTP() {
+	defer s.decodeIn.Close()
+
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(s.reader, header); err != nil {
+			return
+		}
+		if header[0] != '$' {
+			continue // resync: not an interleaved data frame
+		}
+		length := int(header[2])<<8 | int(header[3])
+		packet := make([]byte, length)
+		if _, err := io.ReadFull(s.reader, packet); err != nil {
+			return
+		}
+		if header[1] != s.rtpChannel {
+			continue // RTCP or another track's channel
+		}
+
+		nalUnits := depacketizeH264(packet, &s.sps, &s.pps)
+		for _, nal := range nalUnits {
+			if _, err := s.decodeIn.Write(nal); err != nil {
+				return
+			}
+		}
+	}
+}
+
+var annexBStartCode = []byte{0, 0, 0, 1}
+
+// depacketizeH264 extracts Annex-B-framed NAL units from one RTP packet's
+// payload per RFC 6184: single NAL units (type 1-23) pass through
+// unchanged, STAP-A (24) unpacks its aggregated NALs, and FU-A (28)
+// fragments are reassembled across consecutive packets (assumed to arrive
+// in order, which an interleaved TCP RTSP session - no reordering, no
+// loss - guarantees). SPS (7) and PPS (8) are cached in *sps/*pps and
+// re-emitted ahead of every IDR frame (type 5), since decoders generally
+// expect parameter sets to precede the slice data that depends on them.
+func depacketizeH264(rtpPacket []byte, sps, pps *[]byte) [][]byte {
+	if len(rtpPacket) < 12 {
+		return nil
+	}
+	payload := rtpPacket[12:] // fixed 12-byte RTP header, no CSRC/extension support needed for this source
+	if len(payload) == 0 {
+		return nil
+	}
+
+	var out [][]byte
+	nalType := payload[0] & 0x1F
+
+	switch {
+	case nalType >= 1 && nalType <= 23: // single NAL unit
+		out = append(out, withStartCode(payload))
+
+	case nalType == 24: // STAP-A: a 2-byte size prefix per aggregated NAL
+		i := 1
+		for i+2 <= len(payload) {
+			size := int(payload[i])<<8 | int(payload[i+1])
+			i += 2
+			if i+size > len(payload) {
+				break
+			}
+			out = append(out, withStartCode(payload[i:i+size]))
+			i += size
+		}
+
+	case nalType == 28: // FU-A fragment
+		if len(payload) < 2 {
+			return nil
+		}
+		fuHeader := payload[1]
+		start := fuHeader&0x80 != 0
+		originalType := fuHeader & 0x1F
+		if start {
+			// Reconstruct the original NAL header (forbidden_zero_bit and
+			// nal_ref_idc from the FU indicator, type from the FU header).
+			reconstructed := append([]byte{(payload[0] & 0xE0) | originalType}, payload[2:]...)
+			out = append(out, withStartCode(reconstructed))
+		} else if len(payload) > 2 {
+			out = append(out, payload[2:]) // continuation: appended without its own start code below
+		}
+
+	default:
+		// Unsupported NAL type for this minimal client (e.g. SEI, AUD):
+		// passed through as-is so the decoder can ignore or use it.
+		out = append(out, withStartCode(payload))
+	}
+
+	for _, nal := range out {
+		if len(nal) == 0 {
+			continue
+		}
+		t := nal[len(annexBStartCode)] & 0x1F
+		switch t {
+		case 7:
+			*sps = append([]byte{}, nal...)
+		case 8:
+			*pps = append([]byte{}, nal...)
+		case 5: // IDR: prefix with cached parameter sets so a decoder
+			// joining mid-stream (no prior SPS/PPS of its own) can start.
+			if len(*sps) > 0 && len(*pps) > 0 {
+				out = append([][]byte{*sps, *pps}, out...)
+			}
+		}
+	}
+	return out
+}
+
+func withStartCode(nal []byte) []byte {
+	return append(append([]byte{}, annexBStartCode...), nal...)
+}
+
+// sdpVideoControlURL extracts the "a=control:" attribute of sdp's m=video
+// section and resolves it against base (RTSP allows either an absolute
+// control URL or one relative to the session's base URL).
+func sdpVideoControlURL(sdp []byte, base string) (string, error) {
+	lines := strings.Split(string(sdp), "\n")
+	inVideo := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "m="):
+			inVideo = strings.HasPrefix(line, "m=video")
+		case inVideo && strings.HasPrefix(line, "a=control:"):
+			control := strings.TrimPrefix(line, "a=control:")
+			if strings.Contains(control, "://") {
+				return control, nil
+			}
+			return strings.TrimRight(base, "/") + "/" + control, nil
+		}
+	}
+	return "", fmt.Errorf("capture: no video track with an a=control attribute found in SDP")
+}
+
+func (s *RTSPSource) ReadFrame(buf []byte) (int, error) {
+	return io.ReadFull(s.decodeOut, buf)
+}
+
+func (s *RTSPSource) Info() (width, height int, fps Rational) {
+	return s.Width, s.Height, Rational{}
+}
+
+// Metadata always returns the zero value: nothing in the RTSP/RTP/NAL
+// handshake this source does today parses SDP session-level duration or
+// SPS-embedded aspect ratio (VUI) - a live camera feed has no fixed
+// duration anyway, and the rest would need real VUI parsing to be honest
+// rather than guessed.
+func (s *RTSPSource) Metadata() Metadata {
+	return Metadata{}
+}
+
+func (s *RTSPSource) Close() error {
+	if s.decodeCmd != nil {
+		s.decodeIn.Close()
+		s.decodeCmd.Wait()
+	}
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// Cancel closes the RTSP connection (unblocking feedRTP's read loop) and
+// kills ffmpeg outright, instead of Close's graceful decodeIn-close-then-
+// Wait, so a ReadFrame blocked on a stalled camera returns promptly.
+func (s *RTSPSource) Cancel() error {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	if s.decodeCmd != nil && s.decodeCmd.Process != nil {
+		return s.decodeCmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Command nsigii-serve serves .nsigii files (written by nsigii.go's main())
+// back out as HLS: point it at a directory of .nsigii files and it decodes
+// frames on demand, re-muxes them to H.264/MPEG-TS via ffmpeg, and serves
+// the resulting playlists and segments over HTTP.
+//
+// Usage:
+//
+//	go run ./cmd/nsigii-serve -root ./clips -addr :8080
+//	ffplay "http://localhost:8080/hls/720p/index.m3u8?file=clip.nsigii"
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/obinexus/nsigii-wheel-mailer/nsigiistream"
+)
+
+// defaultQualitiesSpec preserves a single source-resolution rendition for
+// callers that don't pass -qualities.
+const defaultQualitiesSpec = "source:-1:2000"
+
+// parseQualities parses a "-qualities" flag value of comma-separated
+// "name:height:bitrateKbps" entries (height -1 keeps the source size).
+func parseQualities(spec string) ([]nsigiistream.Quality, error) {
+	var qualities []nsigiistream.Quality
+	for _, entry := range strings.Split(spec, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ":")
+		if len(fields) != 3 {
+			log.Fatalf("nsigii-serve: invalid -qualities entry %q (want name:height:bitrateKbps)", entry)
+		}
+		height, err := strconv.Atoi(fields[1])
+		if err != nil {
+			log.Fatalf("nsigii-serve: invalid height in -qualities entry %q: %v", entry, err)
+		}
+		bitrate, err := strconv.Atoi(fields[2])
+		if err != nil {
+			log.Fatalf("nsigii-serve: invalid bitrate in -qualities entry %q: %v", entry, err)
+		}
+		qualities = append(qualities, nsigiistream.Quality{Name: fields[0], Height: height, BitrateKbps: bitrate})
+	}
+	return qualities, nil
+}
+
+func main() {
+	var addr, root, qualitiesSpec, chunkRoot string
+	var frameRate float64
+	var segmentSeconds int
+	var idleTimeout time.Duration
+
+	flag.StringVar(&addr, "addr", ":8080", "HTTP listen address")
+	flag.StringVar(&root, "root", ".", "directory containing .nsigii files; requests reference them by name via ?file=")
+	flag.StringVar(&qualitiesSpec, "qualities", defaultQualitiesSpec, "comma-separated name:height:bitrateKbps renditions")
+	flag.StringVar(&chunkRoot, "chunk-dir", "", "base directory for per-stream .ts chunks (default: OS temp dir)")
+	flag.Float64Var(&frameRate, "fps", 25, "frame rate to assume for every .nsigii file (the container doesn't store one)")
+	flag.IntVar(&segmentSeconds, "segment-seconds", 6, "target duration of each HLS segment")
+	flag.DurationVar(&idleTimeout, "idle-timeout", 2*time.Minute, "how long a stream can go without a request before its ffmpeg process is killed and its chunks pruned")
+	flag.Parse()
+
+	qualities, err := parseQualities(qualitiesSpec)
+	if err != nil {
+		log.Fatalf("nsigii-serve: %v", err)
+	}
+
+	if chunkRoot == "" {
+		chunkRoot = filepath.Join(".", ".nsigii-hls-cache")
+	}
+
+	manager, err := nsigiistream.NewManager(nsigiistream.ManagerConfig{
+		FrameRate:      frameRate,
+		SegmentSeconds: segmentSeconds,
+		IdleTimeout:    idleTimeout,
+		ChunkRoot:      chunkRoot,
+	})
+	if err != nil {
+		log.Fatalf("nsigii-serve: %v", err)
+	}
+	manager.WithQualities(qualities)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hls/", fileRootHandler(root, manager))
+
+	log.Printf("nsigii-serve: serving %s on %s (qualities: %s)", root, addr, qualitiesSpec)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// fileRootHandler resolves the "file" query parameter against root before
+// dispatching to the Manager's playlist or segment handler, so a request
+// can't escape the served directory via "..".
+func fileRootHandler(root string, manager *nsigiistream.Manager) http.HandlerFunc {
+	playlistHandler := manager.PlaylistHandler()
+	segmentHandler := manager.SegmentHandler()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("file")
+		if name == "" || strings.Contains(name, "..") {
+			http.Error(w, "nsigii-serve: missing or invalid file parameter", http.StatusBadRequest)
+			return
+		}
+
+		resolved := filepath.Join(root, name)
+		q := r.URL.Query()
+		q.Set("file", resolved)
+		r.URL.RawQuery = q.Encode()
+
+		if strings.HasSuffix(r.URL.Path, ".m3u8") {
+			playlistHandler(w, r)
+			return
+		}
+		segmentHandler(w, r)
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ============================================================================
+// PLUGGABLE COMPRESSION BACKEND
+// Replaces the hard-coded compress/flate call in NSIGIICodec.EncodeFrame
+// with a selectable Compressor, so a stream can trade ratio for throughput
+// (or vice versa) per the -compress flag.
+// ============================================================================
+
+// Compressor wraps a frame's bytes for a particular backend. Encode returns
+// a WriteCloser; callers must Close it to flush any buffered output before
+// reading back w's contents.
+type Compressor interface {
+	Name() string
+	Encode(w io.Writer) io.WriteCloser
+}
+
+// --- flate -------------------------------------------------------------
+
+type flateCompressor struct{ level int }
+
+func (c *flateCompressor) Name() string { return fmt.Sprintf("flate:%d", c.level) }
+
+func (c *flateCompressor) Encode(w io.Writer) io.WriteCloser {
+	fw, err := flate.NewWriter(w, c.level)
+	if err != nil {
+		// Only invalid levels (outside [-2,9]) can fail here, and
+		// parseCompressorSpec already range-checks them.
+		fw, _ = flate.NewWriter(w, flate.DefaultCompression)
+	}
+	return fw
+}
+
+// --- raw (no compression) ----------------------------------------------
+
+type rawCompressor struct{}
+
+func (rawCompressor) Name() string { return "raw" }
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func (rawCompressor) Encode(w io.Writer) io.WriteCloser { return nopWriteCloser{w} }
+
+// --- zstd ----------------------------------------------------------------
+
+// zstdCompressor wraps klauspost/compress/zstd at a configurable level,
+// optionally seeded with a shared dictionary so short keyframe deltas don't
+// each pay for re-establishing common byte patterns from scratch.
+type zstdCompressor struct {
+	level zstd.EncoderLevel
+	dict  []byte
+}
+
+func (c *zstdCompressor) Name() string {
+	return fmt.Sprintf("zstd:%d", zstdLevelToSpeed(c.level))
+}
+
+func (c *zstdCompressor) Encode(w io.Writer) io.WriteCloser {
+	opts := []zstd.EOption{zstd.WithEncoderLevel(c.level)}
+	if len(c.dict) > 0 {
+		opts = append(opts, zstd.WithEncoderDict(c.dict))
+	}
+	enc, err := zstd.NewWriter(w, opts...)
+	if err != nil {
+		// Falls back to an undictionaried encoder at the same level rather
+		// than failing the whole frame over a bad/stale dictionary.
+		enc, _ = zstd.NewWriter(w, zstd.WithEncoderLevel(c.level))
+	}
+	return enc
+}
+
+func zstdLevelToSpeed(level zstd.EncoderLevel) int {
+	switch level {
+	case zstd.SpeedFastest:
+		return 1
+	case zstd.SpeedDefault:
+		return 3
+	case zstd.SpeedBetterCompression:
+		return 7
+	case zstd.SpeedBestCompression:
+		return 19
+	default:
+		return 3
+	}
+}
+
+// parseCompressorSpec parses a "-compress" flag value of the form
+// "<backend>[:<level>]" ("flate:9", "zstd:19", "raw") into a Compressor.
+// dict is only consulted for the zstd backend.
+func parseCompressorSpec(spec string, dict []byte) (Compressor, error) {
+	name, levelStr, hasLevel := strings.Cut(spec, ":")
+
+	switch name {
+	case "", "flate":
+		level := flate.DefaultCompression
+		if hasLevel {
+			l, err := strconv.Atoi(levelStr)
+			if err != nil || l < -2 || l > 9 {
+				return nil, fmt.Errorf("invalid flate level %q (want -2..9)", levelStr)
+			}
+			level = l
+		}
+		return &flateCompressor{level: level}, nil
+
+	case "zstd":
+		level := zstd.SpeedDefault
+		if hasLevel {
+			n, err := strconv.Atoi(levelStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid zstd level %q", levelStr)
+			}
+			level = zstdSpeedFromLevel(n)
+		}
+		return &zstdCompressor{level: level, dict: dict}, nil
+
+	case "raw":
+		return rawCompressor{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown compression backend %q (want flate, zstd, or raw)", name)
+	}
+}
+
+// zstdSpeedFromLevel maps a zstd CLI-style numeric level (1-22) onto the
+// coarser klauspost/compress/zstd.EncoderLevel buckets it actually exposes.
+func zstdSpeedFromLevel(n int) zstd.EncoderLevel {
+	switch {
+	case n <= 1:
+		return zstd.SpeedFastest
+	case n <= 6:
+		return zstd.SpeedDefault
+	case n <= 12:
+		return zstd.SpeedBetterCompression
+	default:
+		return zstd.SpeedBestCompression
+	}
+}
+
+// ============================================================================
+// STREAM HEADER + DICTIONARY TRAINING
+// ============================================================================
+
+// nsigiiStreamMagic identifies an NSIGII compressed stream so a decoder can
+// tell a dictionary-bearing stream from a bare per-frame one.
+var nsigiiStreamMagic = [4]byte{'N', 'S', 'G', '1'}
+
+// NSIGIIStreamHeader is written once at the start of a stream (not per
+// frame): it carries the backend name and, for zstd, the ID of the shared
+// dictionary every subsequent frame was encoded against, so a decoder can
+// register that dictionary once and reuse it.
+type NSIGIIStreamHeader struct {
+	Backend    string
+	DictID     uint32
+	DictLength uint32
+}
+
+// WriteTo serializes the header as: magic, backend name length+bytes,
+// dict ID, dict length.
+func (h NSIGIIStreamHeader) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.Write(nsigiiStreamMagic[:])
+	binary.Write(&buf, binary.BigEndian, uint16(len(h.Backend)))
+	buf.WriteString(h.Backend)
+	binary.Write(&buf, binary.BigEndian, h.DictID)
+	binary.Write(&buf, binary.BigEndian, h.DictLength)
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// dictTrainSampleFrames is how many leading frames TrainDictionary samples
+// before a stream's dictionary is considered final.
+const dictTrainSampleFrames = 32
+
+// dictMaxSize caps the trained dictionary so it stays cheap to ship ahead of
+// a stream and register on the decoder side.
+const dictMaxSize = 16 * 1024
+
+// trainDictionary builds a shared zstd dictionary from sample frames by
+// picking the most frequently repeated fixed-size chunks across them. This
+// is a simplified stand-in for zstd's COVER/dictBuilder training algorithm
+// (not vendored here) but follows the same principle: a dictionary is only
+// worth shipping if it's built from content the stream actually repeats.
+func trainDictionary(samples [][]byte, maxSize int) []byte {
+	const chunkSize = 64
+
+	counts := make(map[string]int)
+	order := make([]string, 0)
+	for _, sample := range samples {
+		for i := 0; i+chunkSize <= len(sample); i += chunkSize {
+			chunk := string(sample[i : i+chunkSize])
+			if counts[chunk] == 0 {
+				order = append(order, chunk)
+			}
+			counts[chunk]++
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if counts[order[i]] != counts[order[j]] {
+			return counts[order[i]] > counts[order[j]]
+		}
+		return order[i] < order[j] // stable tie-break for deterministic dicts
+	})
+
+	var dict bytes.Buffer
+	for _, chunk := range order {
+		if counts[chunk] < 2 {
+			break // not actually repeated, not worth dictionary space
+		}
+		if dict.Len()+len(chunk) > maxSize {
+			break
+		}
+		dict.WriteString(chunk)
+	}
+	return dict.Bytes()
+}
+
+// dictionaryID hashes a trained dictionary's content so encoder and decoder
+// can agree they're using the same one without shipping it twice.
+func dictionaryID(dict []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(dict)
+	return h.Sum32()
+}
+
+// ============================================================================
+// BENCHMARK HARNESS
+// ============================================================================
+
+// CompressionBenchResult reports one backend's ratio and throughput against
+// a fixed test clip.
+type CompressionBenchResult struct {
+	Backend   string
+	Ratio     float64 // input bytes / output bytes, higher is better
+	MBPerSec  float64
+	InputSize int
+}
+
+// RunCompressionBenchmark compresses clip once per backend spec and reports
+// ratio and MB/s for each, so -bench-compress can print a comparison table
+// without needing a real video file per backend.
+func RunCompressionBenchmark(clip []byte, specs []string) ([]CompressionBenchResult, error) {
+	dict := trainDictionary([][]byte{clip}, dictMaxSize)
+
+	results := make([]CompressionBenchResult, 0, len(specs))
+	for _, spec := range specs {
+		compressor, err := parseCompressorSpec(spec, dict)
+		if err != nil {
+			return nil, fmt.Errorf("benchmarking %q: %w", spec, err)
+		}
+
+		start := time.Now()
+		var out bytes.Buffer
+		wc := compressor.Encode(&out)
+		if _, err := wc.Write(clip); err != nil {
+			return nil, fmt.Errorf("benchmarking %q: %w", spec, err)
+		}
+		if err := wc.Close(); err != nil {
+			return nil, fmt.Errorf("benchmarking %q: %w", spec, err)
+		}
+		elapsed := time.Since(start)
+
+		ratio := 1.0
+		if out.Len() > 0 {
+			ratio = float64(len(clip)) / float64(out.Len())
+		}
+		mbps := float64(len(clip)) / (1024 * 1024) / elapsed.Seconds()
+
+		results = append(results, CompressionBenchResult{
+			Backend:   compressor.Name(),
+			Ratio:     ratio,
+			MBPerSec:  mbps,
+			InputSize: len(clip),
+		})
+	}
+	return results, nil
+}
+
+// runCompressionBenchmarkCLI backs the -bench-compress flag: it reads
+// inputFile whole, runs RunCompressionBenchmark over the comma-separated
+// specs, and prints a ratio/throughput table.
+func runCompressionBenchmarkCLI(inputFile, specsCSV string) {
+	if inputFile == "" {
+		log.Fatalf("-bench-compress requires -input to point at a fixed test clip")
+	}
+	clip, err := os.ReadFile(inputFile)
+	if err != nil {
+		log.Fatalf("reading -input for benchmark: %v", err)
+	}
+
+	specs := strings.Split(specsCSV, ",")
+	for i := range specs {
+		specs[i] = strings.TrimSpace(specs[i])
+	}
+
+	results, err := RunCompressionBenchmark(clip, specs)
+	if err != nil {
+		log.Fatalf("compression benchmark failed: %v", err)
+	}
+
+	fmt.Printf("%-12s %10s %12s %12s\n", "backend", "ratio", "MB/s", "input bytes")
+	for _, r := range results {
+		fmt.Printf("%-12s %10.2f %12.2f %12d\n", r.Backend, r.Ratio, r.MBPerSec, r.InputSize)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+// ============================================================================
+// HTTP CONTROL API (-serve mode)
+// JobManager turns the single-shot encoder into a service a media pipeline
+// (Kerberos.io/Kyoo-style) can drive: POST /jobs enqueues an encode that
+// runs runEncodeJob in its own goroutine, GET /jobs/{id} reports live
+// progress, GET /jobs/{id}/log streams that job's log lines via SSE, and
+// DELETE /jobs/{id} cancels it by canceling the job's context - which
+// runEncodeJob wires into the capture.FrameSource's Cancel(), actually
+// killing the underlying ffmpeg process rather than just abandoning the
+// goroutine.
+// ============================================================================
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobStatus is a Job's lifecycle state.
+type JobStatus string
+
+const (
+	JobQueued   JobStatus = "queued"
+	JobRunning  JobStatus = "running"
+	JobDone     JobStatus = "done"
+	JobFailed   JobStatus = "failed"
+	JobCanceled JobStatus = "canceled"
+)
+
+// Job tracks one enqueued encode: its options, live progress counters
+// (updated by runEncodeJob the same way main()'s pipeline updates its
+// local counters), and a small log ring buffer fanned out to any GET
+// /jobs/{id}/log subscribers.
+type Job struct {
+	ID     string
+	Opts   EncodeOptions
+	cancel context.CancelFunc
+
+	statusMu sync.RWMutex
+	status   JobStatus
+	errMsg   string
+
+	startedAt  time.Time
+	finishedAt time.Time
+
+	// Progress counters: same fields main()'s pipeline tallies locally,
+	// exposed here so JobSnapshot can report them mid-encode.
+	frameCount       int64
+	orderCount       int64
+	chaosCount       int64
+	totalRawSize     int64
+	totalEncodedSize int64
+	estimatedFrames  int64 // 0 if the source's duration/frame rate weren't both known
+
+	logMu sync.Mutex
+	logs  []string
+
+	subsMu sync.Mutex
+	subs   map[chan string]struct{}
+}
+
+// newJob builds a Job in JobQueued state, wired to ctx/cancel so Cancel
+// (driven by DELETE /jobs/{id}) actually stops runEncodeJob's pipeline.
+func newJob(id string, opts EncodeOptions, cancel context.CancelFunc) *Job {
+	return &Job{
+		ID:     id,
+		Opts:   opts,
+		cancel: cancel,
+		status: JobQueued,
+		subs:   make(map[chan string]struct{}),
+	}
+}
+
+// logf records one log line (for JSON/SSE consumers) and also mirrors it
+// to the process-wide logger, so -serve mode's own console output stays
+// as informative as the single-shot CLI path's always was.
+func (j *Job) logf(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...)
+	log.Printf("[%s] %s", j.ID, line)
+
+	j.logMu.Lock()
+	j.logs = append(j.logs, line)
+	j.logMu.Unlock()
+
+	j.subsMu.Lock()
+	for ch := range j.subs {
+		select {
+		case ch <- line:
+		default: // slow subscriber: drop rather than block the encode
+		}
+	}
+	j.subsMu.Unlock()
+}
+
+// subscribe returns a channel fed every future logf line plus a snapshot
+// of lines already recorded (so a late GET /jobs/{id}/log still sees the
+// job's history), and an unsubscribe func the caller must defer.
+func (j *Job) subscribe() (ch chan string, backlog []string, unsubscribe func()) {
+	ch = make(chan string, 64)
+
+	j.logMu.Lock()
+	backlog = append([]string{}, j.logs...)
+	j.logMu.Unlock()
+
+	j.subsMu.Lock()
+	j.subs[ch] = struct{}{}
+	j.subsMu.Unlock()
+
+	return ch, backlog, func() {
+		j.subsMu.Lock()
+		delete(j.subs, ch)
+		j.subsMu.Unlock()
+		close(ch)
+	}
+}
+
+func (j *Job) setStatus(status JobStatus, err error) {
+	j.statusMu.Lock()
+	defer j.statusMu.Unlock()
+	j.status = status
+	if err != nil {
+		j.errMsg = err.Error()
+	}
+}
+
+func (j *Job) getStatus() (JobStatus, string) {
+	j.statusMu.RLock()
+	defer j.statusMu.RUnlock()
+	return j.status, j.errMsg
+}
+
+// JobSnapshot is a Job's JSON representation for GET /jobs/{id}.
+type JobSnapshot struct {
+	ID               string    `json:"id"`
+	Status           JobStatus `json:"status"`
+	Error            string    `json:"error,omitempty"`
+	Input            string    `json:"input"`
+	Output           string    `json:"output"`
+	FrameCount       int64     `json:"frame_count"`
+	OrderCount       int64     `json:"order_count"`
+	ChaosCount       int64     `json:"chaos_count"`
+	TotalRawSize     int64     `json:"total_raw_bytes"`
+	TotalEncodedSize int64     `json:"total_encoded_bytes"`
+	CompressionRatio float64   `json:"compression_ratio_pct"`
+	EstimatedFrames  int64     `json:"estimated_frames,omitempty"`
+	ETASeconds       float64   `json:"eta_seconds,omitempty"`
+}
+
+// Snapshot reads every progress counter atomically and derives the
+// compression ratio and (when the source's duration/frame rate were both
+// known) an ETA from elapsed-time-per-frame-so-far.
+func (j *Job) Snapshot() JobSnapshot {
+	status, errMsg := j.getStatus()
+	raw := atomic.LoadInt64(&j.totalRawSize)
+	encoded := atomic.LoadInt64(&j.totalEncodedSize)
+	frames := atomic.LoadInt64(&j.frameCount)
+	estimated := atomic.LoadInt64(&j.estimatedFrames)
+
+	snap := JobSnapshot{
+		ID:               j.ID,
+		Status:           status,
+		Error:            errMsg,
+		Input:            j.Opts.InputFile,
+		Output:           j.Opts.OutputFile,
+		FrameCount:       frames,
+		OrderCount:       atomic.LoadInt64(&j.orderCount),
+		ChaosCount:       atomic.LoadInt64(&j.chaosCount),
+		TotalRawSize:     raw,
+		TotalEncodedSize: encoded,
+		EstimatedFrames:  estimated,
+	}
+	if raw > 0 && encoded > 0 {
+		snap.CompressionRatio = (1.0 - float64(encoded)/float64(raw)) * 100
+	}
+	if estimated > frames && frames > 0 && !j.startedAt.IsZero() {
+		elapsed := time.Since(j.startedAt).Seconds()
+		perFrame := elapsed / float64(frames)
+		snap.ETASeconds = perFrame * float64(estimated-frames)
+	}
+	return snap
+}
+
+// Cancel stops the job's pipeline (runEncodeJob's ctx.Done() path closes
+// the capture.FrameSource, killing its ffmpeg process) if it's still
+// queued or running.
+func (j *Job) Cancel() bool {
+	status, _ := j.getStatus()
+	if status != JobQueued && status != JobRunning {
+		return false
+	}
+	j.cancel()
+	return true
+}
+
+// JobManager runs encode jobs concurrently, each in its own goroutine,
+// keyed by ID in a sync.Map as the request specifies (a plain map+mutex
+// would do the same job here, but every job's own fields already carry
+// their own locks, so the directory itself needs no more than what
+// sync.Map gives for free).
+type JobManager struct {
+	jobs   sync.Map // id -> *Job
+	nextID int64
+}
+
+func NewJobManager() *JobManager {
+	return &JobManager{}
+}
+
+// Enqueue starts opts encoding in a new goroutine and returns its Job
+// immediately (JobQueued, moving to JobRunning once runEncodeJob begins).
+func (m *JobManager) Enqueue(opts EncodeOptions) *Job {
+	id := fmt.Sprintf("job-%d", atomic.AddInt64(&m.nextID, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	job := newJob(id, opts, cancel)
+	m.jobs.Store(id, job)
+
+	go func() {
+		job.setStatus(JobRunning, nil)
+		job.startedAt = time.Now()
+		err := runEncodeJob(ctx, opts, job)
+		job.finishedAt = time.Now()
+
+		switch {
+		case ctx.Err() == context.Canceled:
+			job.setStatus(JobCanceled, nil)
+		case err != nil:
+			job.setStatus(JobFailed, err)
+		default:
+			job.setStatus(JobDone, nil)
+		}
+	}()
+
+	return job
+}
+
+func (m *JobManager) Get(id string) (*Job, bool) {
+	v, ok := m.jobs.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Job), true
+}
+
+// createJobRequest is POST /jobs's body.
+type createJobRequest struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// serveHTTP builds the mux backing -serve: POST /jobs, GET /jobs/{id},
+// GET /jobs/{id}/log (SSE), DELETE /jobs/{id}. base supplies every
+// encode option POST /jobs doesn't override (FEC shards, consensus
+// timeout, compression spec, transport, workers, ...), i.e. the same
+// flags a single-shot invocation would have used.
+func serveHTTP(addr string, manager *JobManager, base EncodeOptions) error {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "nsigii: only POST is supported on /jobs", http.StatusMethodNotAllowed)
+			return
+		}
+		var req createJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("nsigii: invalid JSON body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Input == "" {
+			http.Error(w, "nsigii: \"input\" is required", http.StatusBadRequest)
+			return
+		}
+
+		opts := base
+		opts.InputFile = req.Input
+		opts.OutputFile = req.Output
+		if opts.OutputFile == "" {
+			opts.OutputFile = deriveOutputName(req.Input)
+		}
+		if req.Width > 0 {
+			opts.Width = req.Width
+		}
+		if req.Height > 0 {
+			opts.Height = req.Height
+		}
+
+		job := manager.Enqueue(opts)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job.Snapshot())
+	})
+
+	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		rest := strings.TrimPrefix(r.URL.Path, "/jobs/")
+		id, sub, _ := strings.Cut(rest, "/")
+
+		job, ok := manager.Get(id)
+		if !ok {
+			http.Error(w, "nsigii: job not found", http.StatusNotFound)
+			return
+		}
+
+		switch {
+		case sub == "" && r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(job.Snapshot())
+
+		case sub == "" && r.Method == http.MethodDelete:
+			if job.Cancel() {
+				w.WriteHeader(http.StatusAccepted)
+			} else {
+				http.Error(w, fmt.Sprintf("nsigii: job %s already finished", id), http.StatusConflict)
+			}
+
+		case sub == "log" && r.Method == http.MethodGet:
+			streamJobLog(w, r, job)
+
+		default:
+			http.Error(w, "nsigii: not found", http.StatusNotFound)
+		}
+	})
+
+	log.Printf("nsigii: serving job control API on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// streamJobLog serves GET /jobs/{id}/log as Server-Sent Events: the
+// job's log backlog first, then every new logf line as it happens, until
+// the job finishes or the client disconnects.
+func streamJobLog(w http.ResponseWriter, r *http.Request, job *Job) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "nsigii: streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch, backlog, unsubscribe := job.subscribe()
+	defer unsubscribe()
+
+	for _, line := range backlog {
+		fmt.Fprintf(w, "data: %s\n\n", line)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case line, open := <-ch:
+			if !open {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", line)
+			flusher.Flush()
+		}
+	}
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"bufio"
 	"bytes"
-	"compress/flate"
+	"container/heap"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/binary"
 	"flag"
 	"fmt"
@@ -11,11 +14,18 @@ import (
 	"log"
 	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/obinexus/nsigii-wheel-mailer/capture"
+	"github.com/obinexus/nsigii-wheel-mailer/ropen/conv"
+	"github.com/obinexus/nsigii-wheel-mailer/ropen/fec"
+	"github.com/obinexus/nsigii-wheel-mailer/trident/consensus"
 )
 
 // ============================================================================
@@ -44,7 +54,7 @@ import (
 
 // Constants for NSIGII Protocol
 const (
-	VERSION         = "7.0.0"
+	VERSION         = "7.1.0"
 	TRIDENT_CHANNELS = 3
 	LOOPBACK_BASE   = "127.0.0."
 	
@@ -63,7 +73,18 @@ const (
 	RWX_WRITE   = 0x02
 	RWX_EXECUTE = 0x01
 	RWX_FULL    = 0x07
-	
+
+	// FEC defaults: 10 data shards to 4 parity shards matches this tree's
+	// loopback topology (3 trident channels) with enough margin to survive
+	// the kind of multi-shard corruption DISCRIMINANT_CHAOS flags.
+	DefaultFECDataShards   = 10
+	DefaultFECParityShards = 4
+
+	// DefaultConvRate preserves EncodeMessage's historical behavior (the
+	// raw, unpunctured rate-1/2 mother code) for channels built without a
+	// -fec-rate override.
+	DefaultConvRate = conv.Rate1_2
+
 	// ROPEN Constants
 	POLARITY_POS = '+'
 	POLARITY_NEG = '-'
@@ -386,12 +407,23 @@ func (t *RBTree) MarkMeasurement(key uint32, conf float64, pol uint8) {
 	if pol != 0 {
 		n.Polarity = pol
 	}
-	
-	// Pruning decision based on confidence and polarity
+
+	// Pruning decision based on confidence and polarity. conf is expected
+	// to be a posterior probability in [0,1) (the ropen/conv Viterbi
+	// decoder's per-bit output, via decodeConvLayer) rather than a raw
+	// measurement, so pruning strength scales with how far below
+	// PRUNE_THRESHOLD it falls instead of firing on a single hard cutoff:
+	// a posterior near 0 prunes on its first low measurement, one only
+	// marginally below the threshold needs several consecutive low
+	// measurements before it's pruned.
 	if conf < PRUNE_THRESHOLD || n.Polarity == POLARITY_NEG {
 		idx := key & 0xFF
 		t.streak[idx]++
-		if t.streak[idx] >= 1 {
+		requiredStreak := int(math.Ceil((1 - conf) * 10))
+		if requiredStreak < 1 {
+			requiredStreak = 1
+		}
+		if t.streak[idx] >= requiredStreak {
 			n.Val = 0
 			n.Confidence = 0.0
 		}
@@ -453,12 +485,28 @@ type TridentPayload struct {
 	MessageHash   [32]byte
 	ContentLength uint32
 	Content       []byte
+	// MerklePath is the leaf layer of the hash tree MessageHash is the root
+	// of: one keyed digest per merkleLeafSize chunk of Content, in order.
+	// The Verifier channel recomputes these to pinpoint which leaf (and so
+	// which byte range) was corrupted, rather than distrusting the whole
+	// frame.
+	MerklePath []MerkleLeaf
 }
 
 type TridentVerification struct {
 	RWXFlags        uint8
 	ConsensusSig    [64]byte
 	HumanRightsTag  string
+	// ParityShards holds the Reed-Solomon parity computed alongside
+	// Payload.Content by EncodeMessage, so VerifyPacket can reconstruct
+	// the content if DISCRIMINANT_CHAOS flags it as corrupted instead of
+	// just XOR-smoothing it with ENZYME_REPAIR.
+	ParityShards [][]byte
+	// ConvCoded is Payload.Content run through the ropen/conv rate-1/2 K=7
+	// convolutional inner code EncodeMessage applies after RiftEncode.
+	// DecodePacket soft-decision-decodes it to feed a per-bit posterior
+	// back into the RB-AVL tree via MarkMeasurement.
+	ConvCoded []byte
 }
 
 type TridentTopology struct {
@@ -474,6 +522,153 @@ type TridentPacket struct {
 	Topology     TridentTopology
 }
 
+// ============================================================================
+// MERKLE/TIGER-TREE CONTENT HASHING
+// Chunks Payload.Content into fixed leaves, keyed-hashes each leaf, and
+// combines them pairwise up to a single root so corruption can be localized
+// to a leaf instead of distrusting the whole frame. There is no blake3 or
+// Tiger import vendored in this tree, so both the leaf and node hash use
+// HMAC-SHA256 (stdlib-only) under distinct domain-separation keys, which
+// gives the same keyed-tree-hash idiom without a new dependency.
+// ============================================================================
+
+const merkleLeafSize = 1024
+
+var (
+	merkleLeafKey = []byte("NSIGII-MERKLE-LEAF-v1")
+	merkleNodeKey = []byte("NSIGII-MERKLE-NODE-v1")
+)
+
+// MerkleLeaf is one leaf of the hash tree rooted at TridentPayload.MessageHash:
+// the keyed digest of content[Start:End].
+type MerkleLeaf struct {
+	Start, End int
+	Digest     [32]byte
+}
+
+func hashMerkleLeaf(data []byte) [32]byte {
+	mac := hmac.New(sha256.New, merkleLeafKey)
+	mac.Write(data)
+	var out [32]byte
+	copy(out[:], mac.Sum(nil))
+	return out
+}
+
+func hashMerkleNode(left, right [32]byte) [32]byte {
+	mac := hmac.New(sha256.New, merkleNodeKey)
+	mac.Write(left[:])
+	mac.Write(right[:])
+	var out [32]byte
+	copy(out[:], mac.Sum(nil))
+	return out
+}
+
+// buildMerkleTree chunks content into merkleLeafSize leaves, hashes each,
+// and combines them pairwise (duplicating the last leaf on an odd level,
+// the standard Bitcoin-style tree convention) up to a single root.
+func buildMerkleTree(content []byte) (root [32]byte, leaves []MerkleLeaf) {
+	if len(content) == 0 {
+		return hashMerkleLeaf(nil), []MerkleLeaf{{Start: 0, End: 0, Digest: hashMerkleLeaf(nil)}}
+	}
+
+	leaves = make([]MerkleLeaf, 0, (len(content)+merkleLeafSize-1)/merkleLeafSize)
+	for start := 0; start < len(content); start += merkleLeafSize {
+		end := start + merkleLeafSize
+		if end > len(content) {
+			end = len(content)
+		}
+		leaves = append(leaves, MerkleLeaf{Start: start, End: end, Digest: hashMerkleLeaf(content[start:end])})
+	}
+
+	level := make([][32]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = leaf.Digest
+	}
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, hashMerkleNode(level[i], level[i+1]))
+			} else {
+				next = append(next, hashMerkleNode(level[i], level[i]))
+			}
+		}
+		level = next
+	}
+	return level[0], leaves
+}
+
+// VerifyMerkle recomputes each leaf digest in packet.Payload.MerklePath from
+// the current Content and rebuilds the root to compare against MessageHash.
+// It returns the index of the first mismatching leaf (-1 if every leaf
+// still matches, including when the whole tree is valid). Callers that need
+// to localize *every* corrupted leaf, not just the first, should use
+// VerifyMerkleLeaves instead.
+func VerifyMerkle(packet TridentPacket) (ok bool, badLeaf int, err error) {
+	ok, badLeaves, err := VerifyMerkleLeaves(packet)
+	if len(badLeaves) == 0 {
+		return ok, -1, err
+	}
+	return ok, badLeaves[0], err
+}
+
+// VerifyMerkleLeaves recomputes each leaf digest in packet.Payload.MerklePath
+// from the current Content and rebuilds the root to compare against
+// MessageHash. It returns the index of every mismatching leaf (nil if every
+// leaf still matches, including when the whole tree is valid) so callers
+// that erase data based on leaf localization - reconstructContent via
+// localizeBadShards, enzymeRepairLocalized - don't stop at the first
+// corrupted leaf and leave later ones unerased.
+func VerifyMerkleLeaves(packet TridentPacket) (ok bool, badLeaves []int, err error) {
+	leaves := packet.Payload.MerklePath
+	if len(leaves) == 0 {
+		return false, nil, fmt.Errorf("nsigii: packet has no MerklePath to verify against")
+	}
+
+	leavesClean := true
+	for i, leaf := range leaves {
+		if leaf.End > len(packet.Payload.Content) || leaf.Start > leaf.End {
+			return false, []int{i}, fmt.Errorf("nsigii: leaf %d range [%d:%d] out of bounds for %d-byte content", i, leaf.Start, leaf.End, len(packet.Payload.Content))
+		}
+		if hashMerkleLeaf(packet.Payload.Content[leaf.Start:leaf.End]) != leaf.Digest {
+			badLeaves = append(badLeaves, i)
+			leavesClean = false
+			// Keep scanning: the root check below still needs every leaf's
+			// *stored* digest (not a recomputed one) to decide whether the
+			// tree itself is internally consistent, and later leaves need
+			// to be localized too.
+		}
+	}
+
+	level := make([][32]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = leaf.Digest
+	}
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, hashMerkleNode(level[i], level[i+1]))
+			} else {
+				next = append(next, hashMerkleNode(level[i], level[i]))
+			}
+		}
+		level = next
+	}
+	root := level[0]
+
+	if root != packet.Payload.MessageHash {
+		if leavesClean {
+			// Every individual leaf recomputed clean, but the stored
+			// MerklePath itself doesn't hash to MessageHash: the tree was
+			// tampered with, not just one leaf's content.
+			return false, nil, fmt.Errorf("nsigii: MerklePath does not combine to MessageHash")
+		}
+		return false, badLeaves, nil
+	}
+	return leavesClean, badLeaves, nil
+}
+
 // ============================================================================
 // QUADRATIC SPLINE INTERPOLATION
 // For smooth frame transitions using Bézier-style quadratic curves
@@ -627,19 +822,162 @@ type TridentChannel struct {
 	RBTree        *RBTree
 	FlashVerifier *FilterFlash
 	Enzyme        *BipolarEnzyme
+
+	// FEC is the Reed-Solomon encoder EncodeMessage shards Payload.Content
+	// through and VerifyPacket reconstructs through on DISCRIMINANT_CHAOS.
+	// FECDataShards/FECParityShards record the shard counts it was built
+	// with, and Reconstructions counts how many times this channel has
+	// repaired a packet via FEC rather than ENZYME_REPAIR.
+	FEC             fec.Encoder
+	FECDataShards   int
+	FECParityShards int
+	Reconstructions uint64
+
+	// Cluster is the 3-node Raft quorum (one voter per trident channel)
+	// VerifyPacket proposes each packet's Merkle root to; nil if this
+	// channel was built without one (see verifyPacketHeuristic).
+	Cluster          *consensus.Cluster
+	ConsensusTimeout time.Duration
+
+	// ConvRate is the code rate (1/2, 2/3, or 3/4) EncodeMessage's
+	// convolutional inner code is punctured to, and DecodePacket's Viterbi
+	// decoder is built for.
+	ConvRate conv.Rate
+
+	// Transport selects how transmitVia/verifyVia carry a packet to this
+	// channel: BusTransportInproc (the default) calls DecodePacket/
+	// VerifyPacket directly; BusTransportGRPC dials BusAddr (or this
+	// channel's loopback address on busPort) and calls the trident bus
+	// server Serve starts there. TLS controls whether that dial - and, on
+	// the server side, the listener Serve opens - uses (m)TLS.
+	Transport BusTransport
+	BusAddr   string
+	TLS       TLSOptions
+	busClient *grpcBusClient
 }
 
 func NewTridentChannel(id uint8) *TridentChannel {
+	return NewTridentChannelWithFEC(id, DefaultFECDataShards, DefaultFECParityShards)
+}
+
+// NewTridentChannelWithFEC builds a TridentChannel whose EncodeMessage and
+// VerifyPacket shard Payload.Content across dataShards+paritySarsh using
+// Reed-Solomon erasure coding, allowing reconstruction of up to
+// parityShards corrupted or missing shards.
+func NewTridentChannelWithFEC(id uint8, dataShards, parityShards int) *TridentChannel {
+	return NewTridentChannelWithConvRate(id, dataShards, parityShards, DefaultConvRate)
+}
+
+// NewTridentChannelWithConvRate is NewTridentChannelWithFEC with the
+// ropen/conv convolutional code rate EncodeMessage/DecodePacket use for
+// the inner channel code exposed (main's -fec-rate flag).
+func NewTridentChannelWithConvRate(id uint8, dataShards, parityShards int, convRate conv.Rate) *TridentChannel {
+	return NewTridentChannelWithBus(id, dataShards, parityShards, convRate, BusTransportInproc, "", TLSOptions{})
+}
+
+// NewTridentChannelWithBus is NewTridentChannelWithConvRate with the
+// transport transmitVia/verifyVia carry packets over exposed (main's
+// -transport/-bind/-tls-* flags): BusTransportInproc keeps the historical
+// direct-method-call behavior, BusTransportGRPC dials busAddr (or this
+// channel's loopback address on busPort if busAddr is empty), optionally
+// over (m)TLS per tlsOpts.
+func NewTridentChannelWithBus(id uint8, dataShards, parityShards int, convRate conv.Rate, transport BusTransport, busAddr string, tlsOpts TLSOptions) *TridentChannel {
+	encoder, err := fec.New(dataShards, parityShards)
+	if err != nil {
+		// Falls back to the package defaults, which are always valid, so a
+		// bad CLI override degrades gracefully instead of panicking.
+		log.Printf("nsigii: invalid FEC shard counts (%d data, %d parity), falling back to defaults: %v",
+			dataShards, parityShards, err)
+		dataShards, parityShards = DefaultFECDataShards, DefaultFECParityShards
+		encoder, _ = fec.New(dataShards, parityShards)
+	}
+
 	return &TridentChannel{
-		ID:            id,
-		LoopbackAddr:  fmt.Sprintf("%s%d", LOOPBACK_BASE, id+1),
-		CodecRatio:    float64(id+1) / 3.0,
-		State:         STATE_ORDER,
-		MessageQueue:  make(chan TridentPacket, 100),
-		RBTree:        NewRBTree(),
-		FlashVerifier: NewFilterFlash(1, 0, -1), // Default quadratic
-		Enzyme:        NewBipolarEnzyme(),
+		ID:              id,
+		LoopbackAddr:    fmt.Sprintf("%s%d", LOOPBACK_BASE, id+1),
+		CodecRatio:      float64(id+1) / 3.0,
+		State:           STATE_ORDER,
+		MessageQueue:    make(chan TridentPacket, 100),
+		RBTree:          NewRBTree(),
+		FlashVerifier:   NewFilterFlash(1, 0, -1), // Default quadratic
+		Enzyme:          NewBipolarEnzyme(),
+		FEC:             encoder,
+		FECDataShards:   dataShards,
+		FECParityShards: parityShards,
+		ConvRate:        convRate,
+		Transport:       transport,
+		BusAddr:         busAddr,
+		TLS:             tlsOpts,
+	}
+}
+
+// dialBus lazily dials (and caches) this channel's trident bus server. Only
+// meaningful when Transport is BusTransportGRPC.
+func (tc *TridentChannel) dialBus() (*grpcBusClient, error) {
+	if tc.busClient != nil {
+		return tc.busClient, nil
+	}
+	addr := tc.BusAddr
+	if addr == "" {
+		addr = fmt.Sprintf("%s:%d", tc.LoopbackAddr, busPort)
+	}
+	client, err := dialTridentBus(addr, tc.TLS)
+	if err != nil {
+		return nil, err
+	}
+	tc.busClient = client
+	return client, nil
+}
+
+// transmitVia carries packet to this channel's Receiver leg: over
+// MessageQueue and a direct DecodePacket call for BusTransportInproc (the
+// queue hand-off is what actually uses the field for the first time -
+// historically packets only ever flowed straight through Go calls), or a
+// Transmit RPC against this channel's trident bus server for
+// BusTransportGRPC. A dial or call failure logs and falls back to the
+// inproc path rather than dropping the frame.
+func (tc *TridentChannel) transmitVia(packet TridentPacket) TridentPacket {
+	tc.MessageQueue <- packet
+	queued := <-tc.MessageQueue
+
+	if tc.Transport != BusTransportGRPC {
+		return tc.DecodePacket(queued)
+	}
+
+	client, err := tc.dialBus()
+	if err != nil {
+		log.Printf("nsigii: trident bus dial failed for channel %d, falling back to inproc: %v", tc.ID, err)
+		return tc.DecodePacket(queued)
+	}
+	decoded, err := client.Transmit(queued)
+	if err != nil {
+		log.Printf("nsigii: trident bus Transmit failed for channel %d, falling back to inproc: %v", tc.ID, err)
+		return tc.DecodePacket(queued)
+	}
+	return decoded
+}
+
+// verifyVia carries packet to this channel's Verifier leg, mirroring
+// transmitVia: a direct VerifyPacket call for BusTransportInproc, or a
+// Verify RPC against this channel's trident bus server for
+// BusTransportGRPC, falling back to the inproc path on any dial or call
+// error.
+func (tc *TridentChannel) verifyVia(packet TridentPacket) (TridentPacket, bool) {
+	if tc.Transport != BusTransportGRPC {
+		return tc.VerifyPacket(packet)
 	}
+
+	client, err := tc.dialBus()
+	if err != nil {
+		log.Printf("nsigii: trident bus dial failed for channel %d, falling back to inproc: %v", tc.ID, err)
+		return tc.VerifyPacket(packet)
+	}
+	result, verified, err := client.Verify(packet)
+	if err != nil {
+		log.Printf("nsigii: trident bus Verify failed for channel %d, falling back to inproc: %v", tc.ID, err)
+		return tc.VerifyPacket(packet)
+	}
+	return result, verified
 }
 
 // EncodeMessage - Transmitter (Channel 0) operation
@@ -658,10 +996,33 @@ func (tc *TridentChannel) EncodeMessage(rawContent []byte) TridentPacket {
 	
 	packet.Payload.Content = encoded
 	packet.Payload.ContentLength = uint32(len(encoded))
-	
-	// Compute SHA-256 hash (simplified to first 32 bytes)
-	copy(packet.Payload.MessageHash[:], encoded[:min(32, len(encoded))])
-	
+
+	// Run the RiftEncode output through the rate-1/2 K=7 convolutional
+	// inner code (DVB generators G1=171, G2=133 octal), punctured to
+	// tc.ConvRate. DecodePacket's Viterbi decoder uses this to derive a
+	// per-bit posterior confidence instead of trusting RiftEncode's flat
+	// initial value of 1.0.
+	packet.Verification.ConvCoded = conv.EncodeBytes(encoded, tc.ConvRate)
+
+	// Build the Merkle tree over the encoded content: MessageHash is the
+	// root, MerklePath is the leaf layer the Verifier channel recomputes to
+	// localize corruption to a byte range instead of the whole frame.
+	root, leaves := buildMerkleTree(encoded)
+	packet.Payload.MessageHash = root
+	packet.Payload.MerklePath = leaves
+
+	// Shard the encoded content and compute Reed-Solomon parity so
+	// VerifyPacket can reconstruct up to FECParityShards corrupted shards
+	// instead of just smoothing them with ENZYME_REPAIR.
+	shards, err := fec.Split(encoded, tc.FECDataShards, tc.FECParityShards)
+	if err != nil {
+		log.Printf("nsigii: FEC split failed, packet will rely on ENZYME_REPAIR: %v", err)
+	} else if err := tc.FEC.Encode(shards); err != nil {
+		log.Printf("nsigii: FEC encode failed, packet will rely on ENZYME_REPAIR: %v", err)
+	} else {
+		packet.Verification.ParityShards = append([][]byte{}, shards[tc.FECDataShards:]...)
+	}
+
 	// Set RWX: Transmitter has WRITE permission
 	packet.Verification.RWXFlags = RWX_WRITE
 	packet.Verification.HumanRightsTag = "NSIGII_HR_TRANSMIT"
@@ -675,12 +1036,17 @@ func (tc *TridentChannel) EncodeMessage(rawContent []byte) TridentPacket {
 
 // DecodePacket - Receiver (Channel 1) operation
 func (tc *TridentChannel) DecodePacket(packet TridentPacket) TridentPacket {
-	// Verify hash integrity
-	computedHash := packet.Payload.Content[:min(32, len(packet.Payload.Content))]
-	if !bytes.Equal(computedHash, packet.Payload.MessageHash[:len(computedHash)]) {
-		log.Println("Hash mismatch in receiver")
+	// Verify Merkle tree integrity, localizing any mismatch to a leaf.
+	if ok, badLeaf, err := VerifyMerkle(packet); err != nil {
+		log.Printf("Merkle verification error in receiver: %v", err)
+	} else if !ok {
+		log.Printf("Merkle hash mismatch in receiver: leaf %d corrupted", badLeaf)
 	}
-	
+
+	// Soft-decision-decode the convolutional inner code and feed its
+	// per-bit posterior back into the RB-AVL tree's pruning.
+	tc.decodeConvLayer(packet)
+
 	// Set RWX: Receiver has READ permission
 	packet.Verification.RWXFlags = RWX_READ
 	
@@ -699,6 +1065,57 @@ func (tc *TridentChannel) DecodePacket(packet TridentPacket) TridentPacket {
 	return packet
 }
 
+// decodeConvLayer soft-decision-decodes packet's convolutional inner code
+// (see EncodeMessage) and writes the resulting per-bit posteriors back
+// into tc.RBTree via MarkMeasurement.
+//
+// This simulated channel only tracks corruption at the granularity of a
+// whole RiftEncode output byte (RBTree.Confidence), not individual coded
+// bits, so every mother-code bit descending from a given byte inherits
+// that byte's confidence as its LLR magnitude via conv.ExpandReliability -
+// a documented simplification in place of an actual per-coded-bit noisy
+// channel model.
+func (tc *TridentChannel) decodeConvLayer(packet TridentPacket) {
+	content := packet.Payload.Content
+	coded := packet.Verification.ConvCoded
+	if len(content) == 0 || len(coded) == 0 {
+		return
+	}
+	numInfoBits := len(content) * 8
+
+	infoReliability := make([]float64, numInfoBits)
+	for i := 0; i < len(content); i++ {
+		conf := 1.0
+		if node := tc.RBTree.Find(uint32(i + 1)); node != nil {
+			conf = node.Confidence
+		}
+		for b := 0; b < 8; b++ {
+			infoReliability[i*8+b] = conf
+		}
+	}
+
+	magnitudes := conv.ExpandReliability(infoReliability, tc.ConvRate)
+	codedBits := conv.BytesToBits(coded)
+	llrs := make([]float64, len(magnitudes))
+	for i, mag := range magnitudes {
+		sign := -1.0
+		if i < len(codedBits) && codedBits[i] == 1 {
+			sign = 1.0
+		}
+		llrs[i] = mag * sign
+	}
+
+	_, posterior := conv.NewDecoder(tc.ConvRate).Decode(llrs, numInfoBits)
+	for i := 0; i < len(content) && i*8 < len(posterior); i++ {
+		p := posterior[i*8]
+		pol := uint8(POLARITY_POS)
+		if p < PRUNE_THRESHOLD {
+			pol = POLARITY_NEG
+		}
+		tc.RBTree.MarkMeasurement(uint32(i+1), p, pol)
+	}
+}
+
 // bipartiteConsensus derives discriminant parameters from payload statistics.
 // Maps content entropy to A=1, B∈[0,4], C=1 so that:
 //   Δ = B² - 4 → ORDER (Δ>0), CONSENSUS (Δ=0), CHAOS (Δ<0)
@@ -717,14 +1134,64 @@ func bipartiteConsensus(content []byte, wheelPos uint8) (a, b, c float64) {
 	totalBits := len(content) * 8
 	base := float64(setBits) / float64(totalBits)
 	wheelCorrect := math.Sin(float64(wheelPos) * math.Pi / 180.0)
-	consensus := math.Abs(base+wheelCorrect) / 2.0
-	return 1.0, consensus * 4.0, 1.0
+	consensusRatio := math.Abs(base+wheelCorrect) / 2.0
+	return 1.0, consensusRatio * 4.0, 1.0
 }
 
-// VerifyPacket - Verifier (Channel 2) operation with discriminant flash verification
+// VerifyPacket - Verifier (Channel 2) operation. When the channel is wired
+// into a trident.Cluster it proposes the packet's Merkle root as a Raft log
+// entry and maps the real commit outcome onto the discriminant states;
+// otherwise it falls back to the single-node bipartiteConsensus heuristic
+// so a channel built without a cluster still degrades gracefully.
 func (tc *TridentChannel) VerifyPacket(packet TridentPacket) (TridentPacket, bool) {
-	// Derive discriminant from bipartite consensus of full payload
-	// (raw pixel bytes give Delta<0 always — consensus formula maps entropy correctly)
+	if tc.Cluster == nil {
+		return tc.verifyPacketHeuristic(packet)
+	}
+
+	outcome, err := tc.Cluster.ProposeRoot(packet.Payload.MessageHash, tc.ConsensusTimeout)
+	if err != nil {
+		log.Printf("nsigii: consensus proposal error on channel %d: %v", tc.ID, err)
+	}
+
+	switch outcome {
+	case consensus.OutcomeOrder:
+		// Unanimous, full-strength commit: coherent, grant full permissions.
+		packet.Verification.RWXFlags = RWX_FULL
+		packet.Topology.WheelPosition = 120
+		tc.State = STATE_ORDER
+		return packet, true
+
+	case consensus.OutcomeConsensus:
+		// Bare-majority (2/3) commit: still durable, but not unanimous.
+		packet.Verification.RWXFlags = RWX_FULL
+		packet.Topology.WheelPosition = 240
+		tc.State = STATE_VERIFIED
+		return packet, true
+
+	default: // consensus.OutcomeChaos
+		// Proposal failed (no leader, timeout): treat content as corrupted.
+		// Prefer reconstructing it from the Reed-Solomon parity shards
+		// EncodeMessage computed; only fall back to the lossy XOR-chain
+		// ENZYME_REPAIR if no usable parity was attached to the packet.
+		repaired, ferr := tc.reconstructContent(packet)
+		if ferr != nil {
+			log.Printf("nsigii: FEC reconstruction unavailable, falling back to ENZYME_REPAIR: %v", ferr)
+			repaired = tc.enzymeRepairLocalized(packet)
+		} else {
+			tc.Reconstructions++
+		}
+		packet.Payload.Content = repaired
+		packet.Verification.RWXFlags = RWX_READ
+		tc.State = STATE_CHAOS
+		return packet, false
+	}
+}
+
+// verifyPacketHeuristic is the original single-node discriminant-flash
+// verifier, derived from bit-population statistics of one packet on one
+// channel. It's kept as the fallback VerifyPacket uses when no Raft
+// cluster is wired in, e.g. a TridentChannel built directly by a test.
+func (tc *TridentChannel) verifyPacketHeuristic(packet TridentPacket) (TridentPacket, bool) {
 	a, b, c := bipartiteConsensus(packet.Payload.Content, packet.Topology.WheelPosition)
 	tc.FlashVerifier.A = a
 	tc.FlashVerifier.B = b
@@ -748,8 +1215,14 @@ func (tc *TridentChannel) VerifyPacket(packet TridentPacket) (TridentPacket, boo
 		return packet, true
 
 	case DISCRIMINANT_CHAOS:
-		// Consensus < 0.5: apply enzyme repair, continue
-		repaired := tc.Enzyme.Execute(ENZYME_REPAIR, packet.Payload.Content)
+		// Consensus < 0.5: the content is flagged corrupted.
+		repaired, err := tc.reconstructContent(packet)
+		if err != nil {
+			log.Printf("nsigii: FEC reconstruction unavailable, falling back to ENZYME_REPAIR: %v", err)
+			repaired = tc.enzymeRepairLocalized(packet)
+		} else {
+			tc.Reconstructions++
+		}
 		packet.Payload.Content = repaired
 		packet.Verification.RWXFlags = RWX_READ
 		tc.State = STATE_CHAOS
@@ -759,6 +1232,120 @@ func (tc *TridentChannel) VerifyPacket(packet TridentPacket) (TridentPacket, boo
 	return packet, false
 }
 
+// enzymeRepairLocalized runs ENZYME_REPAIR only over the byte range
+// VerifyMerkle localizes as corrupted, leaving the rest of the content
+// untouched. It falls back to repairing the whole frame if the MerklePath
+// doesn't let the corrupt leaf be identified.
+func (tc *TridentChannel) enzymeRepairLocalized(packet TridentPacket) []byte {
+	content := packet.Payload.Content
+	_, badLeaf, err := VerifyMerkle(packet)
+	if err != nil || badLeaf < 0 || badLeaf >= len(packet.Payload.MerklePath) {
+		return tc.Enzyme.Execute(ENZYME_REPAIR, content)
+	}
+
+	leaf := packet.Payload.MerklePath[badLeaf]
+	repaired := make([]byte, len(content))
+	copy(repaired, content)
+	copy(repaired[leaf.Start:leaf.End], tc.Enzyme.Execute(ENZYME_REPAIR, content[leaf.Start:leaf.End]))
+	return repaired
+}
+
+// reconstructContent rebuilds packet.Payload.Content from the Reed-Solomon
+// parity shards EncodeMessage attached to it. The current content is
+// re-sharded and checked against the stored parity via FEC.Verify; any
+// data shard that disagrees with parity is treated as erased and repaired
+// through FEC.Reconstruct, rather than assuming the whole payload is bad.
+func (tc *TridentChannel) reconstructContent(packet TridentPacket) ([]byte, error) {
+	if len(packet.Verification.ParityShards) != tc.FECParityShards {
+		return nil, fmt.Errorf("packet carries %d parity shards, channel expects %d",
+			len(packet.Verification.ParityShards), tc.FECParityShards)
+	}
+
+	shards, err := fec.Split(packet.Payload.Content, tc.FECDataShards, tc.FECParityShards)
+	if err != nil {
+		return nil, fmt.Errorf("re-sharding content: %w", err)
+	}
+	copy(shards[tc.FECDataShards:], packet.Verification.ParityShards)
+
+	ok, err := tc.FEC.Verify(shards)
+	if err != nil {
+		return nil, fmt.Errorf("verifying shards: %w", err)
+	}
+	if ok {
+		return packet.Payload.Content, nil
+	}
+
+	// bipartiteConsensus/Raft only flagged the whole payload, not a specific
+	// shard, so localize which byte range actually disagrees with its
+	// Merkle leaf (the same localization enzymeRepairLocalized uses) and
+	// erase just the shard(s) that range overlaps. Reconstruct can only
+	// repair up to FECParityShards erasures; erasing every one of
+	// FECDataShards (10 by default, more than the 4 parity shards) would
+	// always fail.
+	missing, err := tc.localizeBadShards(packet, len(shards[0]))
+	if err != nil {
+		return nil, fmt.Errorf("localizing corrupt shard: %w", err)
+	}
+	if len(missing) > tc.FECParityShards {
+		return nil, fmt.Errorf("%d shards corrupted, can only reconstruct up to %d", len(missing), tc.FECParityShards)
+	}
+	for _, i := range missing {
+		shards[i] = nil
+	}
+
+	if err := tc.FEC.Reconstruct(shards); err != nil {
+		return nil, fmt.Errorf("reconstructing from parity: %w", err)
+	}
+
+	return fec.Join(shards, tc.FECDataShards, len(packet.Payload.Content))
+}
+
+// localizeBadShards maps VerifyMerkleLeaves' corrupted-leaf localization
+// onto the data shard index/indices *every* bad leaf's byte range overlaps,
+// so reconstructContent erases all shards actually known to be bad instead
+// of stopping at the first corrupted leaf and reconstructing from shards
+// that are still wrong.
+func (tc *TridentChannel) localizeBadShards(packet TridentPacket, shardLen int) ([]int, error) {
+	_, badLeaves, err := VerifyMerkleLeaves(packet)
+	if err != nil {
+		return nil, err
+	}
+	if len(badLeaves) == 0 {
+		return nil, fmt.Errorf("no corrupt leaf localized")
+	}
+	if shardLen <= 0 {
+		return nil, fmt.Errorf("invalid shard length %d", shardLen)
+	}
+
+	missingSet := make(map[int]bool)
+	for _, badLeaf := range badLeaves {
+		if badLeaf < 0 || badLeaf >= len(packet.Payload.MerklePath) {
+			return nil, fmt.Errorf("no corrupt leaf localized")
+		}
+
+		leaf := packet.Payload.MerklePath[badLeaf]
+		if leaf.End <= leaf.Start {
+			return nil, fmt.Errorf("corrupt leaf %d has empty range [%d:%d]", badLeaf, leaf.Start, leaf.End)
+		}
+
+		firstShard := leaf.Start / shardLen
+		lastShard := (leaf.End - 1) / shardLen
+		if lastShard >= tc.FECDataShards {
+			lastShard = tc.FECDataShards - 1
+		}
+		for i := firstShard; i <= lastShard; i++ {
+			missingSet[i] = true
+		}
+	}
+
+	missing := make([]int, 0, len(missingSet))
+	for i := range missingSet {
+		missing = append(missing, i)
+	}
+	sort.Ints(missing)
+	return missing, nil
+}
+
 // ============================================================================
 // VIDEO CODEC INTEGRATION
 // Combines RGB24 processing with NSIGII trident verification
@@ -770,24 +1357,154 @@ type NSIGIICodec struct {
 	Channels    [3]*TridentChannel
 	RBTree      *RBTree
 	FlashBuffer *FlashBuffer
+	Cluster     *consensus.Cluster
+
+	// Compressor is the pluggable backend EncodeFrame wraps each frame's
+	// YUV420 bytes through (selected via -compress). dictSamples collects
+	// the first dictTrainSampleFrames frames so TrainDictionary can build a
+	// shared zstd dictionary from real stream content; streamHeaderWritten
+	// guards NSIGIIStreamHeader being emitted only once per stream.
+	Compressor          Compressor
+	Dictionary          []byte
+	dictSamples         [][]byte
+	streamHeaderWritten bool
+	compressSpec        string
+
+	// Transport/BusAddrs/TLS are passed through to every channel's
+	// NewTridentChannelWithBus: Transport selects inproc (direct calls) or
+	// grpc (real sockets, see trident_bus.go); BusAddrs gives a -bind
+	// override per channel (indexed by CHANNEL_*), empty entries falling
+	// back to that channel's loopback address on busPort.
+	Transport BusTransport
+	BusAddrs  [TRIDENT_CHANNELS]string
+	TLS       TLSOptions
+
+	// encodeMu serializes EncodeFrame: it mutates FlashBuffer, the trident
+	// channels' discriminant/consensus chain, and the compressor's
+	// streaming/dictionary-training state, none of which tolerates
+	// concurrent or out-of-order calls. main()'s worker pool holds this
+	// for the encode call only, so frame reads and reassembled writes
+	// still pipeline around it.
+	encodeMu sync.Mutex
 }
 
+// consensusPort is the fixed TCP port the Raft cluster binds on each
+// trident channel's loopback address (127.0.0.{1,2,3}:consensusPort).
+const consensusPort = 9731
+
+// DefaultConsensusTimeout bounds how long VerifyPacket blocks waiting for a
+// Merkle root to commit before treating the proposal as DISCRIMINANT_CHAOS.
+const DefaultConsensusTimeout = 500 * time.Millisecond
+
+// DefaultCompressSpec preserves EncodeFrame's historical behavior (flate at
+// BestCompression) for callers that don't pass -compress.
+const DefaultCompressSpec = "flate:9"
+
 func NewNSIGIICodec(width, height int) *NSIGIICodec {
+	return NewNSIGIICodecWithFEC(width, height, DefaultFECDataShards, DefaultFECParityShards)
+}
+
+// NewNSIGIICodecWithFEC is NewNSIGIICodec with the Reed-Solomon shard counts
+// every trident channel's FEC encoder is built with exposed, so callers
+// (main's -fec-data/-fec-parity flags) can trade reconstruction headroom
+// for parity overhead.
+func NewNSIGIICodecWithFEC(width, height, fecDataShards, fecParityShards int) *NSIGIICodec {
+	return NewNSIGIICodecWithConsensus(width, height, fecDataShards, fecParityShards, DefaultConsensusTimeout)
+}
+
+// NewNSIGIICodecWithConsensus is NewNSIGIICodecWithFEC with the Raft commit
+// timeout each channel's VerifyPacket blocks on exposed, so callers (main's
+// -consensus-timeout flag) can trade latency for how long a flaky peer gets
+// before its proposal is treated as DISCRIMINANT_CHAOS.
+func NewNSIGIICodecWithConsensus(width, height, fecDataShards, fecParityShards int, consensusTimeout time.Duration) *NSIGIICodec {
+	return NewNSIGIICodecWithCompression(width, height, fecDataShards, fecParityShards, consensusTimeout, DefaultCompressSpec)
+}
+
+// NewNSIGIICodecWithCompression is NewNSIGIICodecWithConsensus with the
+// compression backend spec (main's -compress flag, e.g. "zstd:19") exposed.
+func NewNSIGIICodecWithCompression(width, height, fecDataShards, fecParityShards int, consensusTimeout time.Duration, compressSpec string) *NSIGIICodec {
+	return NewNSIGIICodecWithConvRate(width, height, fecDataShards, fecParityShards, consensusTimeout, compressSpec, DefaultConvRate)
+}
+
+// NewNSIGIICodecWithConvRate is NewNSIGIICodecWithCompression with each
+// channel's ropen/conv convolutional code rate exposed (main's -fec-rate
+// flag, e.g. "2/3").
+func NewNSIGIICodecWithConvRate(width, height, fecDataShards, fecParityShards int, consensusTimeout time.Duration, compressSpec string, convRate conv.Rate) *NSIGIICodec {
+	return NewNSIGIICodecWithBus(width, height, fecDataShards, fecParityShards, consensusTimeout, compressSpec, convRate,
+		BusTransportInproc, [TRIDENT_CHANNELS]string{}, TLSOptions{})
+}
+
+// NewNSIGIICodecWithBus is NewNSIGIICodecWithConvRate with the trident bus
+// transport exposed (main's -transport/-bind/-tls-* flags): for
+// BusTransportGRPC, the Receiver and Verifier channels (the two legs
+// EncodeFrame's transmitVia/verifyVia actually dial) each start a trident
+// bus server on busAddrs[CHANNEL_RECEIVER]/busAddrs[CHANNEL_VERIFIER] (or
+// their loopback address on busPort if unset).
+func NewNSIGIICodecWithBus(width, height, fecDataShards, fecParityShards int, consensusTimeout time.Duration, compressSpec string, convRate conv.Rate, transport BusTransport, busAddrs [TRIDENT_CHANNELS]string, tlsOpts TLSOptions) *NSIGIICodec {
 	codec := &NSIGIICodec{
-		Width:       width,
-		Height:      height,
-		RBTree:      NewRBTree(),
-		FlashBuffer: NewFlashBuffer(width * height * 3),
+		Width:        width,
+		Height:       height,
+		RBTree:       NewRBTree(),
+		FlashBuffer:  NewFlashBuffer(width * height * 3),
+		compressSpec: compressSpec,
+		Transport:    transport,
+		BusAddrs:     busAddrs,
+		TLS:          tlsOpts,
 	}
-	
+
+	compressor, err := parseCompressorSpec(compressSpec, nil)
+	if err != nil {
+		log.Printf("nsigii: invalid -compress spec %q, falling back to %q: %v", compressSpec, DefaultCompressSpec, err)
+		compressor, _ = parseCompressorSpec(DefaultCompressSpec, nil)
+		codec.compressSpec = DefaultCompressSpec
+	}
+	codec.Compressor = compressor
+
 	// Initialize three trident channels
 	for i := 0; i < TRIDENT_CHANNELS; i++ {
-		codec.Channels[i] = NewTridentChannel(uint8(i))
+		codec.Channels[i] = NewTridentChannelWithBus(uint8(i), fecDataShards, fecParityShards, convRate, transport, busAddrs[i], tlsOpts)
 	}
-	
+
+	peers := make([]string, TRIDENT_CHANNELS)
+	for i, ch := range codec.Channels {
+		peers[i] = fmt.Sprintf("%s:%d", ch.LoopbackAddr, consensusPort)
+	}
+
+	cluster, err := consensus.NewTridentCluster(peers)
+	if err != nil {
+		// Leaves Cluster nil: every channel's VerifyPacket falls back to
+		// the single-node bipartiteConsensus heuristic instead of failing
+		// the whole codec over a cluster that couldn't start.
+		log.Printf("nsigii: trident consensus cluster unavailable, falling back to single-node verification: %v", err)
+	} else {
+		codec.Cluster = cluster
+	}
+
+	for _, ch := range codec.Channels {
+		ch.Cluster = codec.Cluster
+		ch.ConsensusTimeout = consensusTimeout
+	}
+
+	if transport == BusTransportGRPC {
+		for _, id := range []uint8{CHANNEL_RECEIVER, CHANNEL_VERIFIER} {
+			ch := codec.Channels[id]
+			if _, err := ch.Serve(ch.BusAddr, tlsOpts); err != nil {
+				log.Printf("nsigii: trident bus server failed to start for channel %d, that leg will fall back to inproc: %v", id, err)
+			}
+		}
+	}
+
 	return codec
 }
 
+// VerifyMerkle recomputes packet's Merkle tree and reports whether it's
+// intact, localizing any corruption to a leaf index. It's a thin wrapper
+// over the package-level VerifyMerkle so callers outside this package can
+// validate a packet through the codec alone.
+func (nc *NSIGIICodec) VerifyMerkle(packet TridentPacket) (ok bool, badLeaf int, err error) {
+	return VerifyMerkle(packet)
+}
+
 func (nc *NSIGIICodec) EncodeFrame(frame []byte) ([]byte, error) {
 	if len(frame) != nc.Width*nc.Height*3 {
 		return nil, fmt.Errorf("invalid frame size: expected %d, got %d",
@@ -805,23 +1522,60 @@ func (nc *NSIGIICodec) EncodeFrame(frame []byte) ([]byte, error) {
 	// Channel 0: Transmitter encodes
 	packet := nc.Channels[CHANNEL_TRANSMITTER].EncodeMessage(unified)
 	
-	// Channel 1: Receiver decodes
-	packet = nc.Channels[CHANNEL_RECEIVER].DecodePacket(packet)
-	
-	// Channel 2: Verifier validates with discriminant flash
-	packet, verified := nc.Channels[CHANNEL_VERIFIER].VerifyPacket(packet)
+	// Channel 1: Receiver decodes, over the trident bus transport
+	packet = nc.Channels[CHANNEL_RECEIVER].transmitVia(packet)
+
+	// Channel 2: Verifier validates with discriminant flash, over the
+	// trident bus transport
+	packet, verified := nc.Channels[CHANNEL_VERIFIER].verifyVia(packet)
 	_ = verified // chaos frames still encode after enzyme repair
 	
 	// Convert to YUV420 for compression (from original main.go)
 	yuvFrame := nc.rgbToYUV420(packet.Payload.Content)
-	
-	// Apply DEFLATE compression
-	var buf bytes.Buffer
-	w, _ := flate.NewWriter(&buf, flate.BestCompression)
-	w.Write(yuvFrame)
-	w.Close()
-	
-	return buf.Bytes(), nil
+
+	nc.collectDictionarySample(yuvFrame)
+
+	var out bytes.Buffer
+	if !nc.streamHeaderWritten {
+		header := NSIGIIStreamHeader{
+			Backend:    nc.Compressor.Name(),
+			DictID:     dictionaryID(nc.Dictionary),
+			DictLength: uint32(len(nc.Dictionary)),
+		}
+		if _, err := header.WriteTo(&out); err != nil {
+			return nil, fmt.Errorf("writing stream header: %w", err)
+		}
+		nc.streamHeaderWritten = true
+	}
+
+	wc := nc.Compressor.Encode(&out)
+	if _, err := wc.Write(yuvFrame); err != nil {
+		return nil, fmt.Errorf("compressing frame via %s: %w", nc.Compressor.Name(), err)
+	}
+	if err := wc.Close(); err != nil {
+		return nil, fmt.Errorf("closing %s compressor: %w", nc.Compressor.Name(), err)
+	}
+
+	return out.Bytes(), nil
+}
+
+// collectDictionarySample accumulates up to dictTrainSampleFrames YUV
+// frames and, once enough have arrived, trains a shared dictionary from
+// them and hands it to the zstd backend (a no-op for flate/raw).
+func (nc *NSIGIICodec) collectDictionarySample(yuvFrame []byte) {
+	if len(nc.dictSamples) >= dictTrainSampleFrames || len(nc.Dictionary) > 0 {
+		return
+	}
+
+	sample := append([]byte{}, yuvFrame...)
+	nc.dictSamples = append(nc.dictSamples, sample)
+
+	if len(nc.dictSamples) == dictTrainSampleFrames {
+		nc.Dictionary = trainDictionary(nc.dictSamples, dictMaxSize)
+		if zc, ok := nc.Compressor.(*zstdCompressor); ok && len(nc.Dictionary) > 0 {
+			zc.dict = nc.Dictionary
+		}
+	}
 }
 
 func (nc *NSIGIICodec) rgbToYUV420(rgb []byte) []byte {
@@ -918,57 +1672,6 @@ func readPipedFilename() string {
 	return ""
 }
 
-// probeVideoSize uses ffprobe to detect width/height of a video file
-func probeVideoSize(path string) (int, int, error) {
-	cmd := exec.Command("ffprobe",
-		"-v", "error",
-		"-select_streams", "v:0",
-		"-show_entries", "stream=width,height",
-		"-of", "csv=p=0",
-		path,
-	)
-	out, err := cmd.Output()
-	if err != nil {
-		return 0, 0, fmt.Errorf("ffprobe failed: %v", err)
-	}
-	var w, h int
-	_, err = fmt.Sscanf(strings.TrimSpace(string(out)), "%d,%d", &w, &h)
-	if err != nil {
-		return 0, 0, fmt.Errorf("ffprobe parse failed: %v (output: %s)", err, out)
-	}
-	return w, h, nil
-}
-
-// openRGB24Reader returns an io.ReadCloser of raw RGB24 frames.
-// For .mp4/.mkv/.mov etc: pipes through ffmpeg -pix_fmt rgb24.
-// For .rgb24: opens directly.
-func openRGB24Reader(path string, width, height int) (io.ReadCloser, *exec.Cmd, error) {
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext == ".rgb24" {
-		f, err := os.Open(path)
-		return f, nil, err
-	}
-
-	// Use FFmpeg to decode any video format to raw RGB24 stream
-	cmd := exec.Command("ffmpeg",
-		"-i", path,
-		"-f", "rawvideo",
-		"-pix_fmt", "rgb24",
-		"-vf", fmt.Sprintf("scale=%d:%d", width, height),
-		"-an",       // no audio
-		"-",         // output to stdout
-	)
-	cmd.Stderr = os.Stderr // show ffmpeg progress on stderr
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, nil, fmt.Errorf("ffmpeg pipe failed: %v", err)
-	}
-	if err := cmd.Start(); err != nil {
-		return nil, nil, fmt.Errorf("ffmpeg start failed: %v (is ffmpeg installed?)", err)
-	}
-	return stdout, cmd, nil
-}
-
 // deriveOutputName builds output path from input filename
 func deriveOutputName(inputPath string) string {
 	base := filepath.Base(inputPath)
@@ -977,153 +1680,383 @@ func deriveOutputName(inputPath string) string {
 	return name + ".nsigii"
 }
 
-func main() {
-	var width, height int
-	var inputFile, outputFile string
+// versionHeaderBytes packs a "major.minor.patch" string into the
+// container header's fixed 8-byte Version field, zero-padded the same
+// way the v7.0.0 literal was ({'7','.','0','.','0',0,0,0}).
+func versionHeaderBytes(version string) [8]byte {
+	var b [8]byte
+	copy(b[:], version)
+	return b
+}
+
+// packRational packs a capture.Rational sample aspect ratio into the
+// header's SAR uint32: numerator in the high 16 bits, denominator in the
+// low 16 bits (mirrors how FFmpeg's AVRational is commonly packed when a
+// single machine word is all a format allots it).
+func packRational(r capture.Rational) uint32 {
+	return uint32(uint16(r.Num))<<16 | uint32(uint16(r.Den))
+}
+
+// encodeMetadataDict serializes extra as an AVDictionary-style blob:
+// entry count, then per entry a uint16 key length + key bytes + uint16
+// value length + value bytes, all little-endian to match the rest of the
+// container header. nsigiistream.decodeMetadataDict is the reader half of
+// this (re-implemented there since extra's keys - pix_fmt, codec_name,
+// dar - are free-form strings, not a shared exported type).
+func encodeMetadataDict(extra map[string]string) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(len(extra)))
+	for key, value := range extra {
+		binary.Write(&buf, binary.LittleEndian, uint16(len(key)))
+		buf.WriteString(key)
+		binary.Write(&buf, binary.LittleEndian, uint16(len(value)))
+		buf.WriteString(value)
+	}
+	return buf.Bytes()
+}
+
+// EncodeOptions bundles every parameter a single encode needs, whether it
+// came from main()'s flags (the single-shot CLI path) or a POST /jobs body
+// (JobManager.Enqueue, see jobmanager.go) - the same set main() used to
+// keep as a pile of local flag vars before -serve needed to construct one
+// per HTTP request too.
+type EncodeOptions struct {
+	InputFile, OutputFile string
+	Width, Height         int
+	FECDataShards         int
+	FECParityShards       int
+	ConsensusTimeout      time.Duration
+	CompressSpec          string
+	ConvRate              conv.Rate
+	Transport             BusTransport
+	BusAddrs              [TRIDENT_CHANNELS]string
+	TLSOpts               TLSOptions
+	Workers               int
+}
 
-	flag.IntVar(&width, "width", 0, "video width (0 = auto-detect via ffprobe)")
-	flag.IntVar(&height, "height", 0, "video height (0 = auto-detect via ffprobe)")
-	flag.StringVar(&inputFile, "input", "", "input video file (.mp4, .rgb24, etc.)")
-	flag.StringVar(&outputFile, "output", "", "output file (default: <input>.nsigii)")
+func main() {
+	var opts EncodeOptions
+	var fecRate string
+	var transportFlag string
+	var bindFlag string
+	var tlsCert, tlsKey, tlsCA, tlsWriteCN string
+	var benchCompress string
+	var serveAddr string
+
+	flag.IntVar(&opts.Width, "width", 0, "video width (0 = auto-detect via ffprobe)")
+	flag.IntVar(&opts.Height, "height", 0, "video height (0 = auto-detect via ffprobe)")
+	flag.StringVar(&opts.InputFile, "input", "", "input video file (.mp4, .rgb24, etc.)")
+	flag.StringVar(&opts.OutputFile, "output", "", "output file (default: <input>.nsigii)")
+	flag.IntVar(&opts.FECDataShards, "fec-data", DefaultFECDataShards, "Reed-Solomon data shards per trident packet")
+	flag.IntVar(&opts.FECParityShards, "fec-parity", DefaultFECParityShards, "Reed-Solomon parity shards per trident packet")
+	flag.DurationVar(&opts.ConsensusTimeout, "consensus-timeout", DefaultConsensusTimeout, "max time VerifyPacket blocks for a Merkle root to commit via trident Raft consensus")
+	flag.StringVar(&opts.CompressSpec, "compress", DefaultCompressSpec, "compression backend: flate[:level], zstd[:level], or raw")
+	flag.StringVar(&benchCompress, "bench-compress", "", "comma-separated backend specs to benchmark against -input and exit, e.g. flate:9,zstd:19,raw")
+	flag.StringVar(&fecRate, "fec-rate", DefaultConvRate.String(), "convolutional inner code rate: 1/2, 2/3, or 3/4")
+	flag.StringVar(&transportFlag, "transport", BusTransportInproc.String(), "trident bus transport: inproc (direct calls) or grpc (real sockets, see trident/trident.proto)")
+	flag.StringVar(&bindFlag, "bind", "", "comma-separated bind/dial addresses for the receiver,verifier trident bus servers (grpc transport only; empty entries default to that channel's loopback address)")
+	flag.StringVar(&tlsCert, "tls-cert", "", "trident bus TLS certificate (grpc transport only; enables TLS when set with -tls-key)")
+	flag.StringVar(&tlsKey, "tls-key", "", "trident bus TLS private key")
+	flag.StringVar(&tlsCA, "tls-ca", "", "trident bus CA certificate; enables mTLS (client cert required) when set")
+	flag.StringVar(&tlsWriteCN, "tls-write-cn", "", "comma-separated peer certificate CommonNames granted RWX_WRITE over the trident bus (mTLS only; others get RWX_READ)")
+	flag.IntVar(&opts.Workers, "workers", runtime.NumCPU(), "worker pool size for the reader/encode/writer pipeline; each worker calls codec.EncodeFrame")
+	flag.StringVar(&serveAddr, "serve", "", "run an HTTP job-control API on this address (e.g. :8080) instead of a single-shot encode; POST /jobs, GET /jobs/{id}, GET /jobs/{id}/log (SSE), DELETE /jobs/{id}")
 	flag.Parse()
 
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	var err error
+	opts.ConvRate, err = conv.ParseRate(fecRate)
+	if err != nil {
+		log.Printf("nsigii: %v, falling back to %s", err, DefaultConvRate)
+		opts.ConvRate = DefaultConvRate
+	}
+
+	opts.Transport, err = ParseBusTransport(transportFlag)
+	if err != nil {
+		log.Printf("nsigii: %v, falling back to %s", err, BusTransportInproc)
+		opts.Transport = BusTransportInproc
+	}
+
+	if bindFlag != "" {
+		addrs := strings.Split(bindFlag, ",")
+		for i := 0; i < len(addrs) && i < TRIDENT_CHANNELS; i++ {
+			opts.BusAddrs[i] = strings.TrimSpace(addrs[i])
+		}
+	}
+
+	opts.TLSOpts = TLSOptions{CertFile: tlsCert, KeyFile: tlsKey, CAFile: tlsCA}
+	if tlsWriteCN != "" {
+		for _, cn := range strings.Split(tlsWriteCN, ",") {
+			opts.TLSOpts.WriteCNs = append(opts.TLSOpts.WriteCNs, strings.TrimSpace(cn))
+		}
+	}
+
+	if benchCompress != "" {
+		runCompressionBenchmarkCLI(opts.InputFile, benchCompress)
+		return
+	}
+
+	if serveAddr != "" {
+		manager := NewJobManager()
+		if err := serveHTTP(serveAddr, manager, opts); err != nil {
+			log.Fatalf("nsigii: serve failed: %v", err)
+		}
+		return
+	}
+
 	// ── LTF PIPE MODE ────────────────────────────────────────────────────────
 	// Pattern: '.\video.mp4' | go run .\main.go
 	// PowerShell pipes the filename string to stdin when the file is quoted
-	if inputFile == "" && stdinIsPipe() {
+	if opts.InputFile == "" && stdinIsPipe() {
 		piped := readPipedFilename()
 		if piped != "" {
-			inputFile = piped
-			log.Printf("LTF pipe mode: received input → %s", inputFile)
+			opts.InputFile = piped
+			log.Printf("LTF pipe mode: received input → %s", opts.InputFile)
 		}
 	}
 
-	if inputFile == "" {
+	if opts.InputFile == "" {
 		log.Fatalf("No input specified. Use: '.\\video.mp4' | go run .\\main.go  OR  -input flag")
 	}
 
-	// ── AUTO-DETECT DIMENSIONS ───────────────────────────────────────────────
-	if width == 0 || height == 0 {
-		log.Printf("Probing dimensions: %s", inputFile)
-		w, h, err := probeVideoSize(inputFile)
-		if err != nil {
-			log.Printf("ffprobe failed (%v), using defaults 384x216", err)
-			w, h = 384, 216
-		}
-		if width == 0 {
-			width = w
-		}
-		if height == 0 {
-			height = h
-		}
+	if opts.OutputFile == "" {
+		opts.OutputFile = deriveOutputName(opts.InputFile)
 	}
-	log.Printf("Frame dimensions: %dx%d", width, height)
 
-	// ── DERIVE OUTPUT NAME ────────────────────────────────────────────────────
-	if outputFile == "" {
-		outputFile = deriveOutputName(inputFile)
+	// job is nil here: runEncodeJob falls back to log.Printf directly for
+	// this single-shot path, the same console output main() always had.
+	if err := runEncodeJob(context.Background(), opts, nil); err != nil {
+		log.Fatalf("nsigii: %v", err)
 	}
+}
 
-	log.Printf("NSIGII Polygatic Video Codec v%s", VERSION)
-	log.Printf("Initializing trident channels...")
-	log.Printf("Input:  %s", inputFile)
-	log.Printf("Output: %s", outputFile)
+// runEncodeJob performs one full encode: opening opts.InputFile, writing
+// the NSIGII container header, and running the reader/worker-pool/writer
+// pipeline to opts.OutputFile. It is main()'s former single-shot body,
+// extracted so JobManager.Enqueue (jobmanager.go) can run it concurrently
+// for -serve mode's POST /jobs. ctx's cancellation is wired into the
+// capture.FrameSource via Cancel() (a forceful teardown distinct from the
+// deferred, graceful Close()), so canceling a job actually kills its
+// underlying ffmpeg/RTSP process instead of merely abandoning the
+// goroutine. When job is non-nil its progress counters and log ring
+// buffer are kept in sync for GET /jobs/{id} and GET /jobs/{id}/log;
+// when nil (the CLI path), progress just goes to log.Printf as before.
+func runEncodeJob(ctx context.Context, opts EncodeOptions, job *Job) error {
+	logf := log.Printf
+	if job != nil {
+		logf = job.logf
+	}
 
-	codec := NewNSIGIICodec(width, height)
+	logf("NSIGII Polygatic Video Codec v%s", VERSION)
+	logf("Initializing trident channels...")
+	logf("Input:  %s", opts.InputFile)
+	logf("Output: %s", opts.OutputFile)
 
-	// ── OPEN RGB24 STREAM ─────────────────────────────────────────────────────
-	inputReader, ffmpegCmd, err := openRGB24Reader(inputFile, width, height)
+	// ── OPEN CAPTURE SOURCE ───────────────────────────────────────────────────
+	// inputFile's scheme (file://, rtsp://, astiav://, or a bare path) picks
+	// the backend; width/height of 0 are auto-detected where the backend
+	// supports it (file via ffprobe; rtsp requires -width/-height explicitly).
+	source, err := capture.Open(opts.InputFile, opts.Width, opts.Height)
 	if err != nil {
-		log.Fatalf("Failed to open input: %v", err)
+		return fmt.Errorf("opening input: %w", err)
 	}
-	defer inputReader.Close()
+	defer source.Close()
+
+	// ctx cancellation (DELETE /jobs/{id}, or the CLI path's background
+	// context which never fires) forcefully tears the source down so a
+	// blocked ReadFrame returns instead of leaving the pipeline stuck.
+	go func() {
+		<-ctx.Done()
+		source.Cancel()
+	}()
+
+	width, height := opts.Width, opts.Height
+	var fps capture.Rational
+	width, height, fps = source.Info()
+	if width == 0 || height == 0 {
+		logf("ffprobe/capture failed to determine dimensions, using defaults 384x216")
+		width, height = 384, 216
+	}
+	meta := source.Metadata()
+	logf("Frame dimensions: %dx%d  rate: %d/%d  duration: %dus", width, height, fps.Num, fps.Den, meta.DurationUsec)
+
+	if job != nil && fps.Num > 0 && fps.Den > 0 && meta.DurationUsec > 0 {
+		atomic.StoreInt64(&job.estimatedFrames, int64(meta.DurationUsec)*int64(fps.Num)/(int64(fps.Den)*1_000_000))
+	}
+
+	codec := NewNSIGIICodecWithBus(width, height, opts.FECDataShards, opts.FECParityShards, opts.ConsensusTimeout, opts.CompressSpec, opts.ConvRate,
+		opts.Transport, opts.BusAddrs, opts.TLSOpts)
 
 	// ── CREATE OUTPUT FILE ────────────────────────────────────────────────────
-	output, err := os.Create(outputFile)
+	output, err := os.Create(opts.OutputFile)
 	if err != nil {
-		log.Fatalf("Failed to create output file: %v", err)
+		return fmt.Errorf("creating output file: %w", err)
 	}
 	defer output.Close()
 
-	// Write NSIGII container header
+	// Write the NSIGII container header. The first 32 bytes (Magic through
+	// Reserved) are byte-for-byte what v7.0.0 wrote; everything from
+	// FrameRateNum on is new in v7.1.0 and purely additive, so a v7.0.0
+	// reader that only parses those 32 bytes (there are none in this tree,
+	// but nsigiistream.ReadContainerHeader guards on Version for the same
+	// reason) still works unmodified.
+	metaDict := encodeMetadataDict(meta.Extra)
 	header := struct {
-		Magic      [8]byte
-		Version    [8]byte
-		Width      uint32
-		Height     uint32
-		FrameCount uint32
-		Reserved   uint32
+		Magic        [8]byte
+		Version      [8]byte
+		Width        uint32
+		Height       uint32
+		FrameCount   uint32
+		Reserved     uint32
+		FrameRateNum uint32
+		FrameRateDen uint32
+		DurationUsec uint64
+		SAR          uint32
+		MetaLength   uint32
 	}{
-		Magic:   [8]byte{'N', 'S', 'I', 'G', 'I', 'I', 0, 0},
-		Version: [8]byte{'7', '.', '0', '.', '0', 0, 0, 0},
-		Width:   uint32(width),
-		Height:  uint32(height),
+		Magic:        [8]byte{'N', 'S', 'I', 'G', 'I', 'I', 0, 0},
+		Version:      versionHeaderBytes(VERSION),
+		Width:        uint32(width),
+		Height:       uint32(height),
+		FrameRateNum: uint32(fps.Num),
+		FrameRateDen: uint32(fps.Den),
+		DurationUsec: meta.DurationUsec,
+		SAR:          packRational(meta.SAR),
+		MetaLength:   uint32(len(metaDict)),
 	}
 	binary.Write(output, binary.LittleEndian, header)
+	output.Write(metaDict)
+
+	// CFR assumption for PTS: real-time pacing from a variable frame rate
+	// source would need per-frame timestamps this codec doesn't have
+	// (capture.FrameSource reports one rate for the whole stream), so PTS
+	// is derived from frame index times this fixed spacing instead.
+	var frameDurationUsec int64
+	if fps.Num > 0 && fps.Den > 0 {
+		frameDurationUsec = int64(1_000_000) * int64(fps.Den) / int64(fps.Num)
+	}
 
 	frameSize := width * height * 3
-	frame := make([]byte, frameSize)
-	frameCount := 0
-	chaosCount := 0
-	orderCount := 0
-	totalRawSize := 0
-	totalEncodedSize := 0
-
-	log.Println("Encoding frames with trident verification...")
-
-	for {
-		n, err := io.ReadFull(inputReader, frame)
-		if err == io.EOF {
-			break
-		}
-		// ErrUnexpectedEOF = partial last frame, still encode it
-		if err != nil && err != io.ErrUnexpectedEOF {
-			// Non-fatal pipe errors (e.g. FFmpeg closed early): drain and stop cleanly
-			log.Printf("Frame read ended: %v (encoded %d frames so far)", err, frameCount)
-			break
-		}
-		if n == 0 {
-			break
-		}
-
-		totalRawSize += n
-
-		encoded, encErr := codec.EncodeFrame(frame[:n])
-		if encErr != nil {
-			// Size mismatch on partial last frame — skip, do not break
-			chaosCount++
-			continue
-		}
-
-		// Track discriminant state from verifier channel
-		switch codec.Channels[CHANNEL_VERIFIER].State {
-		case STATE_ORDER, STATE_VERIFIED:
-			orderCount++
-		default:
-			chaosCount++
+	var frameCount, chaosCount, orderCount, totalRawSize, totalEncodedSize int64
+	workers := opts.Workers
+
+	logf("Encoding frames with trident verification (%d workers)...", workers)
+
+	// ── PIPELINE ──────────────────────────────────────────────────────────────
+	// Three stages connected by bounded channels, the same keyframe-parallel
+	// shape Kyoo's transcoder uses for segment encoding: a reader tags each
+	// RGB24 frame with a monotonic seq, a worker pool calls codec.EncodeFrame,
+	// and a writer reassembles results in seq order (via resultHeap, a small
+	// min-heap) before they hit disk. codec.EncodeFrame itself stays behind
+	// encodeMu: it mutates the codec's shared FlashBuffer halves, the trident
+	// channels' discriminant/consensus chain, and the compressor's
+	// streamHeaderWritten/dictionary-training state, none of which is safe
+	// (or, for the consensus chain, even meaningful) to run out of order -
+	// so the speedup this pipeline buys is reader/writer I/O and frame
+	// preparation overlapping the encode step, not the encode step itself
+	// running on multiple cores at once.
+	jobs := make(chan frameJob, workers*2)
+	results := make(chan encodeResult, workers*2)
+
+	go func() {
+		defer close(jobs)
+		seq := 0
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			buf := make([]byte, frameSize)
+			n, err := source.ReadFrame(buf)
+			if err == io.EOF {
+				return
+			}
+			// ErrUnexpectedEOF = partial last frame, still encode it
+			if err != nil && err != io.ErrUnexpectedEOF {
+				// Non-fatal source errors (e.g. FFmpeg closed early, RTSP
+				// session dropped): stop cleanly rather than treating it
+				// as fatal
+				logf("Frame read ended: %v (read %d frames so far)", err, seq)
+				return
+			}
+			if n == 0 {
+				return
+			}
+			jobs <- frameJob{seq: seq, data: buf[:n]}
+			seq++
 		}
+	}()
+
+	var workerWG sync.WaitGroup
+	workerWG.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer workerWG.Done()
+			for job := range jobs {
+				codec.encodeMu.Lock()
+				encoded, err := codec.EncodeFrame(job.data)
+				verifierState := codec.Channels[CHANNEL_VERIFIER].State
+				codec.encodeMu.Unlock()
+				results <- encodeResult{seq: job.seq, rawLen: len(job.data), encoded: encoded, verifierState: verifierState, err: err}
+			}
+		}()
+	}
+	go func() {
+		workerWG.Wait()
+		close(results)
+	}()
+
+	pending := &resultHeap{}
+	heap.Init(pending)
+	next := 0
+	for res := range results {
+		heap.Push(pending, res)
+
+		for pending.Len() > 0 && (*pending)[0].seq == next {
+			r := heap.Pop(pending).(encodeResult)
+			next++
+			atomic.AddInt64(&totalRawSize, int64(r.rawLen))
+
+			if r.err != nil {
+				// Size mismatch on partial last frame — skip, do not write
+				atomic.AddInt64(&chaosCount, 1)
+				continue
+			}
 
-		frameHeader := struct{ Size uint32 }{Size: uint32(len(encoded))}
-		binary.Write(output, binary.LittleEndian, frameHeader)
-		output.Write(encoded)
-
-		totalEncodedSize += 4 + len(encoded)
-		frameCount++
+			switch r.verifierState {
+			case STATE_ORDER, STATE_VERIFIED:
+				atomic.AddInt64(&orderCount, 1)
+			default:
+				atomic.AddInt64(&chaosCount, 1)
+			}
 
-		if frameCount%50 == 0 {
-			log.Printf("Encoded %d frames  [ORDER:%d  CHAOS:%d]", frameCount, orderCount, chaosCount)
+			frameHeader := struct {
+				PTS  int64
+				Size uint32
+			}{PTS: int64(r.seq) * frameDurationUsec, Size: uint32(len(r.encoded))}
+			binary.Write(output, binary.LittleEndian, frameHeader)
+			output.Write(r.encoded)
+
+			atomic.AddInt64(&totalEncodedSize, int64(12+len(r.encoded)))
+			count := atomic.AddInt64(&frameCount, 1)
+
+			if job != nil {
+				atomic.StoreInt64(&job.frameCount, count)
+				atomic.StoreInt64(&job.orderCount, atomic.LoadInt64(&orderCount))
+				atomic.StoreInt64(&job.chaosCount, atomic.LoadInt64(&chaosCount))
+				atomic.StoreInt64(&job.totalRawSize, atomic.LoadInt64(&totalRawSize))
+				atomic.StoreInt64(&job.totalEncodedSize, atomic.LoadInt64(&totalEncodedSize))
+			}
+			if count%50 == 0 {
+				logf("Encoded %d frames  [ORDER:%d  CHAOS:%d]", count, atomic.LoadInt64(&orderCount), atomic.LoadInt64(&chaosCount))
+			}
 		}
 	}
 
-	// Drain any remaining FFmpeg output to prevent broken pipe signal
-	if ffmpegCmd != nil {
-		io.Copy(io.Discard, inputReader)
-		if err := ffmpegCmd.Wait(); err != nil {
-			// Broken pipe is expected when we stop reading — not an error
-			if !strings.Contains(err.Error(), "exit status") {
-				log.Printf("FFmpeg: %v", err)
-			}
-		}
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
 	// Update frame count in header (offset 24)
@@ -1135,14 +2068,53 @@ func main() {
 		compressionRatio = (1.0 - float64(totalEncodedSize)/float64(totalRawSize)) * 100
 	}
 
-	log.Printf("\n=== NSIGII Encoding Complete ===")
-	log.Printf("Frames encoded:     %d", frameCount)
-	log.Printf("ORDER (verified):   %d", orderCount)
-	log.Printf("CHAOS (repaired):   %d", chaosCount)
-	log.Printf("Raw size:           %d bytes", totalRawSize)
-	log.Printf("Encoded size:       %d bytes", totalEncodedSize)
-	log.Printf("Compression ratio:  %.2f%%", compressionRatio)
-	log.Printf("Trident channels:   TRANSMIT → RECEIVE → VERIFY")
-	log.Printf("Flash verification: Discriminant / Bipartite Consensus")
-	log.Printf("Output file:        %s", outputFile)
+	logf("\n=== NSIGII Encoding Complete ===")
+	logf("Frames encoded:     %d", frameCount)
+	logf("ORDER (verified):   %d", orderCount)
+	logf("CHAOS (repaired):   %d", chaosCount)
+	logf("Raw size:           %d bytes", totalRawSize)
+	logf("Encoded size:       %d bytes", totalEncodedSize)
+	logf("Compression ratio:  %.2f%%", compressionRatio)
+	logf("Trident channels:   TRANSMIT → RECEIVE → VERIFY")
+	logf("Flash verification: Discriminant / Bipartite Consensus")
+	logf("Output file:        %s", opts.OutputFile)
+	return nil
+}
+
+// frameJob is one reader-tagged RGB24 frame awaiting encode, seq giving
+// its position in the original stream so the writer can reassemble
+// out-of-order worker results.
+type frameJob struct {
+	seq  int
+	data []byte
+}
+
+// encodeResult is one worker's output for a frameJob: the encoded bytes
+// (or err, for a partial-final-frame size mismatch), the verifier
+// channel's State read inside the worker alongside the encode call (under
+// encodeMu) so the writer can tally ORDER/CHAOS deterministically without
+// re-touching codec state itself.
+type encodeResult struct {
+	seq           int
+	rawLen        int
+	encoded       []byte
+	verifierState uint8
+	err           error
+}
+
+// resultHeap orders encodeResults by seq so the writer goroutine can pull
+// them out in original frame order even though workers finish out of
+// order.
+type resultHeap []encodeResult
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].seq < h[j].seq }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(encodeResult)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
 }
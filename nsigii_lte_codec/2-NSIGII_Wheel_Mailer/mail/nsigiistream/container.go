@@ -0,0 +1,446 @@
+// Package nsigiistream reads back the .nsigii container nsigii.go's main()
+// writes: ContainerHeader/BuildFrameIndex parse the fixed file header and
+// per-frame [size][payload] records, and Decoder turns one frame's payload
+// back into playable RGB24 by reversing EncodeFrame's compression and
+// YUV420 packing, so cmd/nsigii-serve can re-mux it to H.264 and serve it
+// as HLS.
+//
+// EncodeFrame's RiftEncode step folds every two input bytes into one via a
+// nibble-conjugate XOR (see RiftEncode and NewFlashBuffer's Unite in
+// nsigii.go) before the trident channels verify and compress it - that
+// fold isn't information-preserving, so Decoder reconstructs the signal
+// the trident channels actually verified and wrote to disk, not a
+// bit-identical copy of the original input frames. That matches this
+// codec's existing lossy/experimental framing (EPSILON_PAD, ENZYME_REPAIR)
+// rather than a defect introduced here.
+package nsigiistream
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// containerMagic is the 8-byte Magic field nsigii.go's main() writes at the
+// start of every .nsigii file.
+var containerMagic = [8]byte{'N', 'S', 'I', 'G', 'I', 'I', 0, 0}
+
+// ContainerHeader mirrors the anonymous header struct main() writes with
+// binary.Write(..., binary.LittleEndian, header) before the first frame.
+// Magic through Reserved are the fixed 32 bytes every version has always
+// written; FrameRateNum through Metadata were added in v7.1.0 and are
+// zero/nil on an older container (see ReadContainerHeader).
+type ContainerHeader struct {
+	Magic      [8]byte
+	Version    [8]byte
+	Width      uint32
+	Height     uint32
+	FrameCount uint32
+	Reserved   uint32
+
+	FrameRateNum uint32 // 0 if unknown or read from a pre-7.1.0 container
+	FrameRateDen uint32
+	DurationUsec uint64
+	SAR          uint32            // packed as numerator<<16 | denominator, see nsigii.go's packRational
+	Metadata     map[string]string // nil on a pre-7.1.0 container
+}
+
+// v71HeaderFields is the portion of ContainerHeader that v7.1.0 appends
+// after the original 32-byte header (FrameRateNum through MetaLength);
+// MetaLength itself isn't kept on ContainerHeader since the dictionary it
+// describes is inlined into Metadata once parsed.
+type v71HeaderFields struct {
+	FrameRateNum uint32
+	FrameRateDen uint32
+	DurationUsec uint64
+	SAR          uint32
+	MetaLength   uint32
+}
+
+// hasV71Fields reports whether version is "7.1.0" or later, i.e. whether
+// a reader should expect the extended header fields and per-frame PTS
+// nsigii.go started writing in that version. Compared as raw bytes (the
+// field has no numeric encoding of its own); every version this codec has
+// shipped sorts correctly under that comparison.
+func hasV71Fields(version [8]byte) bool {
+	v71 := [8]byte{'7', '.', '1', '.', '0', 0, 0, 0}
+	return bytes.Compare(version[:], v71[:]) >= 0
+}
+
+// ReadContainerHeader reads and validates the container header at the
+// start of r: the fixed 32-byte prefix every version has written, plus
+// (when Version indicates v7.1.0 or later) the extended fields and
+// metadata dictionary that follow it.
+func ReadContainerHeader(r io.Reader) (ContainerHeader, error) {
+	var base struct {
+		Magic      [8]byte
+		Version    [8]byte
+		Width      uint32
+		Height     uint32
+		FrameCount uint32
+		Reserved   uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &base); err != nil {
+		return ContainerHeader{}, fmt.Errorf("nsigiistream: reading container header: %w", err)
+	}
+	if base.Magic != containerMagic {
+		return ContainerHeader{}, fmt.Errorf("nsigiistream: bad container magic %q, not an .nsigii file", base.Magic[:])
+	}
+
+	h := ContainerHeader{
+		Magic:      base.Magic,
+		Version:    base.Version,
+		Width:      base.Width,
+		Height:     base.Height,
+		FrameCount: base.FrameCount,
+		Reserved:   base.Reserved,
+	}
+	if !hasV71Fields(h.Version) {
+		return h, nil
+	}
+
+	var ext v71HeaderFields
+	if err := binary.Read(r, binary.LittleEndian, &ext); err != nil {
+		return ContainerHeader{}, fmt.Errorf("nsigiistream: reading v7.1 container header fields: %w", err)
+	}
+	h.FrameRateNum, h.FrameRateDen, h.DurationUsec, h.SAR = ext.FrameRateNum, ext.FrameRateDen, ext.DurationUsec, ext.SAR
+
+	if ext.MetaLength > 0 {
+		blob := make([]byte, ext.MetaLength)
+		if _, err := io.ReadFull(r, blob); err != nil {
+			return ContainerHeader{}, fmt.Errorf("nsigiistream: reading metadata dictionary: %w", err)
+		}
+		meta, err := decodeMetadataDict(blob)
+		if err != nil {
+			return ContainerHeader{}, err
+		}
+		h.Metadata = meta
+	}
+	return h, nil
+}
+
+// decodeMetadataDict reverses nsigii.go's encodeMetadataDict: a uint32
+// entry count, then per entry a uint16 key length + key bytes + uint16
+// value length + value bytes, all little-endian.
+func decodeMetadataDict(blob []byte) (map[string]string, error) {
+	buf := bytes.NewReader(blob)
+
+	var count uint32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return nil, fmt.Errorf("nsigiistream: reading metadata dictionary entry count: %w", err)
+	}
+
+	dict := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		key, err := readDictString(buf)
+		if err != nil {
+			return nil, fmt.Errorf("nsigiistream: reading metadata dictionary key %d: %w", i, err)
+		}
+		value, err := readDictString(buf)
+		if err != nil {
+			return nil, fmt.Errorf("nsigiistream: reading metadata dictionary value %d: %w", i, err)
+		}
+		dict[key] = value
+	}
+	return dict, nil
+}
+
+// readDictString reads one uint16-length-prefixed string, the shared
+// wire shape encodeMetadataDict uses for both keys and values.
+func readDictString(r *bytes.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return "", err
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// FrameRecord locates one frame's payload within the container file. PTS
+// is 0 for every frame in a pre-7.1.0 container (it didn't record one).
+type FrameRecord struct {
+	Offset int64 // start of the payload, i.e. past its size/PTS prefix
+	Length int64
+	PTS    int64 // microseconds, per nsigii.go's CFR-derived frame timestamps
+}
+
+// BuildFrameIndex walks every frame record following the container
+// header and returns each payload's offset, length, and PTS, so a Decoder
+// can seek straight to a given frame instead of re-reading the file from
+// the start. It stops at header.FrameCount records or EOF, whichever
+// comes first (main() writes FrameCount after the fact, via a seek back
+// to offset 24, so a truncated or still-being-written file is read only
+// as far as it actually goes). header.Version decides the record shape:
+// v7.1.0 and later prefix each frame with an int64 PTS ahead of the
+// uint32 size that's all pre-7.1.0 containers wrote.
+func BuildFrameIndex(r io.ReadSeeker, header ContainerHeader) ([]FrameRecord, error) {
+	records := make([]FrameRecord, 0, header.FrameCount)
+	withPTS := hasV71Fields(header.Version)
+
+	for uint32(len(records)) < header.FrameCount || header.FrameCount == 0 {
+		var pts int64
+		var size uint32
+
+		if withPTS {
+			var frameHeader struct {
+				PTS  int64
+				Size uint32
+			}
+			if err := binary.Read(r, binary.LittleEndian, &frameHeader); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, fmt.Errorf("nsigiistream: reading frame %d header: %w", len(records), err)
+			}
+			pts, size = frameHeader.PTS, frameHeader.Size
+		} else {
+			var frameHeader struct{ Size uint32 }
+			if err := binary.Read(r, binary.LittleEndian, &frameHeader); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, fmt.Errorf("nsigiistream: reading frame %d size: %w", len(records), err)
+			}
+			size = frameHeader.Size
+		}
+
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("nsigiistream: locating frame %d: %w", len(records), err)
+		}
+		records = append(records, FrameRecord{Offset: offset, Length: int64(size), PTS: pts})
+
+		if _, err := r.Seek(int64(size), io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("nsigiistream: skipping frame %d payload: %w", len(records)-1, err)
+		}
+	}
+
+	return records, nil
+}
+
+// streamHeaderMagic is compressor.go's nsigiiStreamMagic: the marker
+// NSIGIICodec.EncodeFrame writes once, at the start of the first frame's
+// payload only, identifying the compression backend every frame in the
+// file was encoded with.
+var streamHeaderMagic = [4]byte{'N', 'S', 'G', '1'}
+
+// streamHeader is compressor.go's NSIGIIStreamHeader, re-parsed here since
+// it's unexported in package main.
+type streamHeader struct {
+	Backend    string
+	DictID     uint32
+	DictLength uint32
+}
+
+// readStreamHeader reads a streamHeader from the front of data if present,
+// returning the header, whether one was found, and the remaining bytes
+// (the frame's actual compressed payload).
+func readStreamHeader(data []byte) (streamHeader, bool, []byte) {
+	if len(data) < 4 || [4]byte(data[:4]) != streamHeaderMagic {
+		return streamHeader{}, false, data
+	}
+	buf := bytes.NewReader(data[4:])
+
+	var nameLen uint16
+	if err := binary.Read(buf, binary.BigEndian, &nameLen); err != nil {
+		return streamHeader{}, false, data
+	}
+	nameBytes := make([]byte, nameLen)
+	if _, err := io.ReadFull(buf, nameBytes); err != nil {
+		return streamHeader{}, false, data
+	}
+
+	var h streamHeader
+	h.Backend = string(nameBytes)
+	if err := binary.Read(buf, binary.BigEndian, &h.DictID); err != nil {
+		return streamHeader{}, false, data
+	}
+	if err := binary.Read(buf, binary.BigEndian, &h.DictLength); err != nil {
+		return streamHeader{}, false, data
+	}
+
+	consumed := len(data) - buf.Len()
+	return h, true, data[consumed:]
+}
+
+// decompress reverses parseCompressorSpec's "<backend>[:level]" encoders
+// (compressor.go): backend is the bare name (e.g. "flate", "zstd", "raw"),
+// without its level suffix, since decoding doesn't depend on the level the
+// encoder chose.
+func decompress(backend string, dictLength uint32, payload []byte) ([]byte, error) {
+	name, _, _ := bytesCut(backend, ':')
+
+	switch name {
+	case "", "flate":
+		fr := flate.NewReader(bytes.NewReader(payload))
+		defer fr.Close()
+		out, err := io.ReadAll(fr)
+		if err != nil {
+			return nil, fmt.Errorf("nsigiistream: inflating flate frame: %w", err)
+		}
+		return out, nil
+
+	case "raw":
+		return payload, nil
+
+	case "zstd":
+		if dictLength > 0 {
+			// EncodeFrame trains its zstd dictionary from the stream's own
+			// first frames but only ever writes the dictionary's ID and
+			// length to the container, not its content (see
+			// NSIGIIStreamHeader.WriteTo), so a standalone decoder has no
+			// way to obtain the bytes needed to reverse a dictionaried
+			// encode without that dictionary shipped out of band.
+			return nil, fmt.Errorf("nsigiistream: frame was zstd-compressed with a %d-byte shared dictionary that isn't stored in the container; decode requires that dictionary out of band", dictLength)
+		}
+		zr, err := zstd.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("nsigiistream: opening zstd frame: %w", err)
+		}
+		defer zr.Close()
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("nsigiistream: inflating zstd frame: %w", err)
+		}
+		return out, nil
+
+	default:
+		return nil, fmt.Errorf("nsigiistream: unknown compression backend %q", name)
+	}
+}
+
+func bytesCut(s string, sep byte) (before, after string, found bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == sep {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// Decoder reads frames back out of one open .nsigii file, in order or by
+// random access once its frame index is built.
+type Decoder struct {
+	file   *os.File
+	Header ContainerHeader
+	frames []FrameRecord
+
+	backend    string
+	dictLength uint32
+}
+
+// Open parses path's container header and frame index, ready for
+// DecodeFrame calls.
+func Open(path string) (*Decoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("nsigiistream: opening %s: %w", path, err)
+	}
+
+	header, err := ReadContainerHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	records, err := BuildFrameIndex(f, header)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Decoder{file: f, Header: header, frames: records}, nil
+}
+
+// Close releases the underlying file.
+func (d *Decoder) Close() error { return d.file.Close() }
+
+// FrameCount reports how many frames Open actually indexed (which may be
+// fewer than d.Header.FrameCount for a file still being written).
+func (d *Decoder) FrameCount() int { return len(d.frames) }
+
+// DecodeFrame reads frame index i and returns it as packed RGB24, the
+// inverse of NSIGIICodec.rgbToYUV420 applied to the frame the trident
+// channels actually wrote (see this file's package doc for what that
+// means for fidelity against the original input).
+func (d *Decoder) DecodeFrame(i int) ([]byte, error) {
+	if i < 0 || i >= len(d.frames) {
+		return nil, fmt.Errorf("nsigiistream: frame %d out of range (have %d)", i, len(d.frames))
+	}
+	rec := d.frames[i]
+
+	payload := make([]byte, rec.Length)
+	if _, err := d.file.ReadAt(payload, rec.Offset); err != nil {
+		return nil, fmt.Errorf("nsigiistream: reading frame %d payload: %w", i, err)
+	}
+
+	if i == 0 {
+		if h, ok, rest := readStreamHeader(payload); ok {
+			d.backend, d.dictLength = h.Backend, h.DictLength
+			payload = rest
+		}
+	}
+	if d.backend == "" {
+		d.backend = "flate" // DefaultCompressSpec's bare backend name
+	}
+
+	yuv, err := decompress(d.backend, d.dictLength, payload)
+	if err != nil {
+		return nil, fmt.Errorf("nsigiistream: decompressing frame %d: %w", i, err)
+	}
+
+	return yuv420ToRGB(yuv, int(d.Header.Width), int(d.Header.Height)), nil
+}
+
+// yuv420ToRGB reverses NSIGIICodec.rgbToYUV420's BT.601-style conversion:
+// each 2x2 luma block shares one chroma sample, matching the 4:2:0
+// subsampling rgbToYUV420 wrote.
+func yuv420ToRGB(yuv []byte, width, height int) []byte {
+	rgb := make([]byte, width*height*3)
+
+	ySize := width * height
+	uSize := ySize / 4
+	if len(yuv) < ySize+2*uSize {
+		return rgb // short/corrupt frame: return a black frame rather than index out of range
+	}
+	yPlane := yuv[:ySize]
+	uPlane := yuv[ySize : ySize+uSize]
+	vPlane := yuv[ySize+uSize : ySize+2*uSize]
+
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			y := float64(yPlane[j*width+i])
+			uvIdx := (j/2)*(width/2) + (i / 2)
+			u := float64(uPlane[uvIdx]) - 128
+			v := float64(vPlane[uvIdx]) - 128
+
+			r := y + 1.402*v
+			g := y - 0.344136*u - 0.714136*v
+			b := y + 1.772*u
+
+			idx := (j*width + i) * 3
+			rgb[idx] = clampByte(r)
+			rgb[idx+1] = clampByte(g)
+			rgb[idx+2] = clampByte(b)
+		}
+	}
+	return rgb
+}
+
+func clampByte(x float64) byte {
+	if x < 0 {
+		return 0
+	}
+	if x > 255 {
+		return 255
+	}
+	return byte(x)
+}
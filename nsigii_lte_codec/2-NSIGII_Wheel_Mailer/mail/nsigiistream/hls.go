@@ -0,0 +1,445 @@
+package nsigiistream
+
+// ============================================================================
+// HLS STREAMING SERVER
+// Modeled on the go-vod/transcoder pattern: a Manager keyed by file path
+// holds one Stream per requested Quality; each Stream owns an ffmpeg child
+// process fed this package's decoded RGB24 frames over stdin, which writes
+// numbered MPEG-TS segments into a temp dir via ffmpeg's segment muxer.
+// PlaylistHandler/SegmentHandler serve the HLS side of that: the playlist
+// is assembled directly from the container's FrameCount and the
+// configured frame rate (never parsed back out of ffmpeg's own output),
+// and segments are read straight off disk once ffmpeg has written them.
+// ============================================================================
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quality is one output rendition a Stream can be transcoded to.
+type Quality struct {
+	Name        string // URL path segment, e.g. "720p"
+	Height      int    // -1 keeps the source height
+	BitrateKbps int
+}
+
+// ManagerConfig holds the settings every Stream a Manager creates shares.
+type ManagerConfig struct {
+	// FrameRate is assumed for every .nsigii file, since the container
+	// format (see ReadContainerHeader) doesn't carry one.
+	FrameRate float64
+	// SegmentSeconds is the target duration of each .ts chunk.
+	SegmentSeconds int
+	// IdleTimeout is how long a Stream can go without a request before its
+	// ffmpeg process is killed and its chunk directory pruned.
+	IdleTimeout time.Duration
+	// ChunkRoot is the base temp directory; each Stream gets its own
+	// subdirectory under it.
+	ChunkRoot string
+}
+
+// Manager serves HLS for any number of .nsigii files and qualities,
+// starting a Stream for a given (path, quality) pair on first request and
+// tearing it down after ManagerConfig.IdleTimeout of inactivity.
+type Manager struct {
+	cfg          ManagerConfig
+	cfgQualities []Quality
+
+	mu      sync.Mutex
+	streams map[string]*Stream
+}
+
+// NewManager builds a Manager; cfg.ChunkRoot is created if it doesn't
+// already exist.
+func NewManager(cfg ManagerConfig) (*Manager, error) {
+	if err := os.MkdirAll(cfg.ChunkRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("nsigiistream: creating chunk root %s: %w", cfg.ChunkRoot, err)
+	}
+	return &Manager{cfg: cfg, streams: make(map[string]*Stream)}, nil
+}
+
+func streamKey(path string, q Quality) string { return path + "|" + q.Name }
+
+// streamFor returns the Stream for (path, quality), starting it if this is
+// the first request for that pair.
+func (m *Manager) streamFor(path string, q Quality) (*Stream, error) {
+	key := streamKey(path, q)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.streams[key]; ok {
+		return s, nil
+	}
+
+	s, err := newStream(path, q, m.cfg)
+	if err != nil {
+		return nil, err
+	}
+	m.streams[key] = s
+	go m.watchIdle(key, s)
+	return s, nil
+}
+
+// watchIdle periodically checks s for inactivity and, once IdleTimeout has
+// elapsed since its last request, stops it and drops it from m.streams so
+// the next request starts a fresh one.
+func (m *Manager) watchIdle(key string, s *Stream) {
+	ticker := time.NewTicker(m.cfg.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if time.Since(s.lastAccess()) < m.cfg.IdleTimeout {
+			continue
+		}
+
+		m.mu.Lock()
+		delete(m.streams, key)
+		m.mu.Unlock()
+
+		s.stop()
+		return
+	}
+}
+
+// PlaylistHandler serves GET /hls/{quality}/index.m3u8?file={path}: an
+// HLS playlist whose segment count and #EXTINF durations are computed
+// directly from the container's FrameCount and cfg.FrameRate, not from
+// anything ffmpeg has produced yet.
+func (m *Manager) PlaylistHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path, quality, ok := m.parseRequest(r, "index.m3u8")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		s, err := m.streamFor(path, quality)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.touch()
+
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		io.WriteString(w, s.playlist())
+	}
+}
+
+// SegmentHandler serves GET /hls/{quality}/{segment}.ts?file={path},
+// restarting the Stream's ffmpeg process at the requested segment's frame
+// offset first if that segment isn't the one it's currently producing.
+func (m *Manager) SegmentHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		segmentName := filepath.Base(r.URL.Path)
+		path, quality, ok := m.parseRequest(r, segmentName)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		index, err := segmentIndex(segmentName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s, err := m.streamFor(path, quality)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.touch()
+
+		data, err := s.segment(index)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "video/mp2t")
+		w.Write(data)
+	}
+}
+
+// parseRequest pulls the quality name out of r.URL.Path (the path segment
+// immediately before tail) and the source file out of the "file" query
+// parameter, resolving quality against m.cfg's known list.
+func (m *Manager) parseRequest(r *http.Request, tail string) (path string, quality Quality, ok bool) {
+	path = r.URL.Query().Get("file")
+	if path == "" {
+		return "", Quality{}, false
+	}
+
+	trimmed := strings.TrimSuffix(r.URL.Path, "/"+tail)
+	qualityName := filepath.Base(trimmed)
+
+	for _, q := range m.qualities() {
+		if q.Name == qualityName {
+			return path, q, true
+		}
+	}
+	return "", Quality{}, false
+}
+
+// qualities is the fixed set PlaylistHandler/SegmentHandler resolve
+// against; cmd/nsigii-serve wires this list in via WithQualities.
+func (m *Manager) qualities() []Quality { return m.cfgQualities }
+
+// WithQualities records the set of Quality rungs this Manager serves.
+// Kept as a setter (rather than a ManagerConfig field) so the qualities
+// list and ManagerConfig's scalar settings can be supplied independently
+// in cmd/nsigii-serve's flag parsing.
+func (m *Manager) WithQualities(qualities []Quality) *Manager {
+	m.cfgQualities = qualities
+	return m
+}
+
+func segmentIndex(name string) (int, error) {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.TrimPrefix(name, "seg_")
+	n, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, fmt.Errorf("nsigiistream: bad segment name %q", name)
+	}
+	return n, nil
+}
+
+// Stream owns one (file, quality) rendition: a Decoder over the source
+// .nsigii file, and an ffmpeg process re-muxing the frames it decodes into
+// numbered .ts chunks under dir.
+type Stream struct {
+	path    string
+	quality Quality
+	cfg     ManagerConfig
+	dir     string
+
+	decoder *Decoder
+
+	mu           sync.Mutex
+	cmd          *exec.Cmd
+	stdin        io.WriteCloser
+	fromFrame    int // frame index the running ffmpeg process was started at
+	lastAccessAt time.Time
+	feedDone     chan struct{}
+}
+
+func newStream(path string, q Quality, cfg ManagerConfig) (*Stream, error) {
+	decoder, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(cfg.ChunkRoot, "nsigii-hls-*")
+	if err != nil {
+		decoder.Close()
+		return nil, fmt.Errorf("nsigiistream: creating chunk dir: %w", err)
+	}
+
+	s := &Stream{
+		path:         path,
+		quality:      q,
+		cfg:          cfg,
+		dir:          dir,
+		decoder:      decoder,
+		lastAccessAt: time.Now(),
+	}
+	if err := s.restartFrom(0); err != nil {
+		decoder.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Stream) touch() {
+	s.mu.Lock()
+	s.lastAccessAt = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *Stream) lastAccess() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastAccessAt
+}
+
+// stop kills this Stream's ffmpeg process (if any) and removes its chunk
+// directory; called once by Manager.watchIdle after IdleTimeout elapses.
+func (s *Stream) stop() {
+	s.mu.Lock()
+	cmd, done := s.cmd, s.feedDone
+	s.mu.Unlock()
+
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+	if done != nil {
+		<-done
+	}
+	s.decoder.Close()
+	os.RemoveAll(s.dir)
+}
+
+// playlist renders an #EXTM3U VOD playlist whose segment count and
+// durations are computed from the decoder's FrameCount and cfg.FrameRate
+// (not from whatever ffmpeg has actually written to s.dir so far - a
+// player is free to request a segment ahead of what's been produced,
+// which segment() below blocks on).
+func (s *Stream) playlist() string {
+	totalSeconds := float64(s.decoder.FrameCount()) / s.cfg.FrameRate
+	segmentCount := int(totalSeconds/float64(s.cfg.SegmentSeconds)) + 1
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:%d\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-MEDIA-SEQUENCE:0\n",
+		s.cfg.SegmentSeconds)
+
+	remaining := totalSeconds
+	for i := 0; i < segmentCount; i++ {
+		dur := float64(s.cfg.SegmentSeconds)
+		if remaining < dur {
+			dur = remaining
+		}
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\nseg_%05d.ts\n", dur, i)
+		remaining -= dur
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.String()
+}
+
+// segmentFramesPerChunk is how many decoded frames feed() hands ffmpeg
+// before one .ts chunk's worth of input has been written, used to decide
+// whether a requested segment needs a restart at a new -ss-equivalent
+// frame offset.
+func (s *Stream) segmentFramesPerChunk() int {
+	return int(s.cfg.FrameRate * float64(s.cfg.SegmentSeconds))
+}
+
+// segment waits for (or triggers) ffmpeg producing the .ts chunk at index
+// and returns its bytes. If index isn't within the run currently feeding
+// ffmpeg - a seek backward, or far enough ahead that waiting for it inline
+// would be unreasonable - it restarts ffmpeg at that segment's frame
+// offset first, the equivalent of handing a file-based ffmpeg a fresh -ss.
+func (s *Stream) segment(index int) ([]byte, error) {
+	wantFrame := index * s.segmentFramesPerChunk()
+
+	s.mu.Lock()
+	needsRestart := index < s.segmentFromIndex() || wantFrame-s.fromFrame > 4*s.segmentFramesPerChunk()
+	s.mu.Unlock()
+
+	if needsRestart {
+		if err := s.restartFrom(wantFrame); err != nil {
+			return nil, err
+		}
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("seg_%05d.ts", index))
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		if data, err := os.ReadFile(path); err == nil {
+			return data, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("nsigiistream: timed out waiting for segment %d", index)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (s *Stream) segmentFromIndex() int {
+	return s.fromFrame / s.segmentFramesPerChunk()
+}
+
+// restartFrom kills any ffmpeg process currently running for this Stream,
+// clears its chunk dir, and starts a fresh one fed from decoder frame
+// fromFrame, numbering its first output chunk to match (so playlist()'s
+// segment numbering - always from frame 0 - lines up with what's on disk).
+func (s *Stream) restartFrom(fromFrame int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cmd != nil && s.cmd.Process != nil {
+		s.cmd.Process.Kill()
+		<-s.feedDone
+	}
+
+	entries, _ := os.ReadDir(s.dir)
+	for _, e := range entries {
+		os.Remove(filepath.Join(s.dir, e.Name()))
+	}
+
+	height := s.quality.Height
+	scale := "-2:-2" // keep source size
+	if height > 0 {
+		scale = fmt.Sprintf("-2:%d", height)
+	}
+	startSegment := fromFrame / s.segmentFramesPerChunk()
+
+	cmd := exec.Command("ffmpeg",
+		"-y",
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+		"-s", fmt.Sprintf("%dx%d", s.decoder.Header.Width, s.decoder.Header.Height),
+		"-r", strconv.FormatFloat(s.cfg.FrameRate, 'f', -1, 64),
+		"-i", "-",
+		"-an",
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-pix_fmt", "yuv420p",
+		"-vf", "scale="+scale,
+		"-b:v", fmt.Sprintf("%dk", s.quality.BitrateKbps),
+		"-f", "segment",
+		"-segment_time", strconv.Itoa(s.cfg.SegmentSeconds),
+		"-segment_format", "mpegts",
+		"-reset_timestamps", "1",
+		"-segment_start_number", strconv.Itoa(startSegment),
+		filepath.Join(s.dir, "seg_%05d.ts"),
+	)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("nsigiistream: opening ffmpeg stdin: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("nsigiistream: starting ffmpeg (is it installed?): %w", err)
+	}
+
+	s.cmd = cmd
+	s.stdin = stdin
+	s.fromFrame = fromFrame
+	s.feedDone = make(chan struct{})
+
+	go s.feed(fromFrame, stdin, s.feedDone)
+	return nil
+}
+
+// feed writes every decoded frame from fromFrame onward to stdin, stopping
+// on the first write error (ffmpeg exited, e.g. because restartFrom killed
+// it) or once the source file is exhausted, closing stdin either way so
+// ffmpeg flushes its last segment.
+func (s *Stream) feed(fromFrame int, stdin io.WriteCloser, done chan struct{}) {
+	defer close(done)
+	defer stdin.Close()
+
+	for i := fromFrame; i < s.decoder.FrameCount(); i++ {
+		frame, err := s.decoder.DecodeFrame(i)
+		if err != nil {
+			log.Printf("nsigiistream: decoding frame %d for %s: %v", i, s.path, err)
+			return
+		}
+		if _, err := stdin.Write(frame); err != nil {
+			return
+		}
+	}
+}
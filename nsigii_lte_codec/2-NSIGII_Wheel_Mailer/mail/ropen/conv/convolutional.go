@@ -0,0 +1,374 @@
+// Package conv implements the rate-1/2, constraint-length-7 convolutional
+// code DVB-S (and most satellite/LTE physical layers) use as the inner
+// channel code, plus a soft-decision Viterbi decoder and the standard
+// puncturing tables for rate-matching to 2/3 and 3/4. It is applied after
+// ropen's RiftEncode sparse-duplex stage: RiftEncode's output becomes this
+// package's information bits.
+package conv
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rate selects how many of the mother code's bits survive per pair of
+// information bits, mirroring the code-rate switch a DVB-S receiver makes
+// to trade error resilience for throughput.
+type Rate int
+
+const (
+	Rate1_2 Rate = iota // no puncturing: the raw rate-1/2 mother code
+	Rate2_3
+	Rate3_4
+)
+
+func (r Rate) String() string {
+	switch r {
+	case Rate1_2:
+		return "1/2"
+	case Rate2_3:
+		return "2/3"
+	case Rate3_4:
+		return "3/4"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRate parses a "-fec-rate" flag value ("1/2", "2/3", "3/4").
+func ParseRate(s string) (Rate, error) {
+	switch s {
+	case "", "1/2":
+		return Rate1_2, nil
+	case "2/3":
+		return Rate2_3, nil
+	case "3/4":
+		return Rate3_4, nil
+	default:
+		return Rate1_2, fmt.Errorf("conv: unknown code rate %q (want 1/2, 2/3, or 3/4)", s)
+	}
+}
+
+const (
+	// ConstraintLength is K for this code: each output bit depends on the
+	// current input bit plus the previous K-1 bits of shift-register state.
+	ConstraintLength = 7
+	memoryBits       = ConstraintLength - 1 // 6 bits of shift-register state
+	numStates        = 1 << memoryBits      // 64
+
+	// genPoly1/genPoly2 are the standard DVB/CCSDS rate-1/2 K=7 generator
+	// polynomials, G1=171 and G2=133 octal, applied to the 7-bit register
+	// (current input bit as the MSB, followed by the 6 bits of state).
+	genPoly1 = 0171
+	genPoly2 = 0133
+
+	// TracebackDepth is the minimum survivor path length the Viterbi
+	// decoder carries before committing to a bit decision, chosen as the
+	// conventional 5xK for a code of this constraint length.
+	TracebackDepth = 5 * ConstraintLength
+	tracebackWindow = 2 * TracebackDepth
+)
+
+// puncturePatterns are the ETSI EN 300 421 (DVB-S) puncturing tables,
+// applied cyclically over the rate-1/2 mother code's interleaved (g1,g2)
+// bit stream: true keeps a bit, false drops it before transmission.
+var puncturePatterns = map[Rate][]bool{
+	Rate1_2: {true, true},
+	Rate2_3: {true, true, false, true},             // X1 Y1 Y2 per 2 info bits
+	Rate3_4: {true, true, false, true, true, false}, // X1 Y1 Y2 X3 per 3 info bits
+}
+
+func parity(x uint8) uint8 {
+	x ^= x >> 4
+	x ^= x >> 2
+	x ^= x >> 1
+	return x & 1
+}
+
+func bipolar(bit uint8) float64 {
+	if bit == 1 {
+		return 1
+	}
+	return -1
+}
+
+type branch struct {
+	nextState  uint8
+	g1, g2     uint8
+}
+
+// trellis[s][b] is the transition taken from state s on input bit b.
+var trellis [numStates][2]branch
+
+func init() {
+	for s := 0; s < numStates; s++ {
+		for b := uint8(0); b < 2; b++ {
+			reg := (b << 6) | uint8(s)
+			trellis[s][b] = branch{
+				nextState: reg >> 1,
+				g1:        parity(reg & genPoly1),
+				g2:        parity(reg & genPoly2),
+			}
+		}
+	}
+}
+
+// Encoder is a rate-1/2 K=7 convolutional encoder with a 6-bit shift
+// register carried across calls to EncodeBits, so a caller can stream
+// information bits in multiple chunks before Flush.
+type Encoder struct {
+	state uint8
+	rate  Rate
+}
+
+// NewEncoder builds an Encoder whose EncodeBytes/EncodeBits puncture down
+// to rate.
+func NewEncoder(rate Rate) *Encoder {
+	return &Encoder{rate: rate}
+}
+
+// EncodeBits runs bits (each 0 or 1) through the mother code and returns
+// the unpunctured (g1,g2) output bits, interleaved. Puncturing is applied
+// once, over the whole info+flush stream, by EncodeBytes/Puncture - not
+// here - so that a multi-call caller's puncture phase stays continuous.
+func (e *Encoder) EncodeBits(bits []uint8) []uint8 {
+	mother := make([]uint8, 0, len(bits)*2)
+	for _, b := range bits {
+		reg := (b << 6) | e.state
+		g1 := parity(reg & genPoly1)
+		g2 := parity(reg & genPoly2)
+		e.state = reg >> 1
+		mother = append(mother, g1, g2)
+	}
+	return mother
+}
+
+// Flush drives the shift register back to the all-zero state by encoding
+// memoryBits zero bits, the standard zero-tail termination a decoder can
+// rely on to trace back the final few symbols unambiguously.
+func (e *Encoder) Flush() []uint8 {
+	return e.EncodeBits(make([]uint8, memoryBits))
+}
+
+// EncodeBytes convolutionally encodes data at rate, appends a zero-tail
+// flush, punctures the result down to rate, and packs it back into bytes.
+func EncodeBytes(data []byte, rate Rate) []byte {
+	e := NewEncoder(rate)
+	mother := e.EncodeBits(BytesToBits(data))
+	mother = append(mother, e.Flush()...)
+	return BitsToBytes(Puncture(mother, rate))
+}
+
+// Puncture drops mother-code bits per rate's cyclic keep/drop pattern.
+func Puncture(mother []uint8, rate Rate) []uint8 {
+	pattern := puncturePatterns[rate]
+	out := make([]uint8, 0, len(mother))
+	for i, b := range mother {
+		if pattern[i%len(pattern)] {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+func punctureF(mother []float64, rate Rate) []float64 {
+	pattern := puncturePatterns[rate]
+	out := make([]float64, 0, len(mother))
+	for i, v := range mother {
+		if pattern[i%len(pattern)] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// depuncture reinserts a 0 (erasure) LLR at every position Puncture
+// dropped, reconstructing the full motherLen-long mother-code LLR stream
+// depunctured expects.
+func depuncture(punctured []float64, rate Rate, motherLen int) []float64 {
+	pattern := puncturePatterns[rate]
+	out := make([]float64, motherLen)
+	j := 0
+	for i := 0; i < motherLen; i++ {
+		if pattern[i%len(pattern)] {
+			if j < len(punctured) {
+				out[i] = punctured[j]
+			}
+			j++
+		}
+	}
+	return out
+}
+
+// ExpandReliability maps one reliability value per information bit (plus
+// an implicit full-confidence value for the flush tail's memoryBits zero
+// bits) onto one reliability value per surviving, post-puncture
+// mother-code bit, repeating each information bit's reliability across
+// the two mother bits it produced. This lets a channel that only tracks
+// per-information-bit confidence (like RBTree.Confidence) still drive
+// per-coded-bit branch metrics in Decoder.Decode.
+func ExpandReliability(infoBitReliability []float64, rate Rate) []float64 {
+	numSteps := len(infoBitReliability) + memoryBits
+	mother := make([]float64, 0, numSteps*2)
+	for t := 0; t < numSteps; t++ {
+		r := 1.0
+		if t < len(infoBitReliability) {
+			r = infoBitReliability[t]
+		}
+		mother = append(mother, r, r)
+	}
+	return punctureF(mother, rate)
+}
+
+// BytesToBits unpacks data into one uint8 (0 or 1) per bit, MSB first.
+func BytesToBits(data []byte) []uint8 {
+	bits := make([]uint8, 0, len(data)*8)
+	for _, b := range data {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, (b>>uint(i))&1)
+		}
+	}
+	return bits
+}
+
+// BitsToBytes packs bits (each 0 or 1, MSB first within a byte) back up,
+// zero-padding the last byte if len(bits) isn't a multiple of 8.
+func BitsToBytes(bits []uint8) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b != 0 {
+			out[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return out
+}
+
+// Decoder soft-decision-decodes a rate-matched Viterbi codeword.
+type Decoder struct {
+	rate Rate
+}
+
+// NewDecoder builds a Decoder for codewords punctured to rate.
+func NewDecoder(rate Rate) *Decoder {
+	return &Decoder{rate: rate}
+}
+
+// step records, for every trellis state at one time step, the winning
+// predecessor state/bit and the raw branch metric that won it - carried
+// forward so Decode can emit a soft per-bit reliability alongside the
+// hard decision.
+type step struct {
+	prevState [numStates]uint8
+	prevBit   [numStates]uint8
+	branchMag [numStates]float64
+}
+
+// Decode runs soft-decision Viterbi decoding over punctured, a
+// puncture-rate LLR stream (positive => bit 1 more likely, magnitude =>
+// reliability) covering numInfoBits information bits plus the implicit
+// memoryBits zero-tail flush. It carries a survivor path of
+// tracebackWindow (>= 2xTracebackDepth) steps before committing to a bit
+// decision, and returns the decoded hard bits (length numInfoBits)
+// alongside a per-bit posterior-like reliability in [0,1) derived from
+// the winning branch metric at each step - a simplified stand-in for a
+// full forward-backward (BCJR) posterior, which this package does not
+// implement.
+func (d *Decoder) Decode(punctured []float64, numInfoBits int) (bits []uint8, reliability []float64) {
+	if numInfoBits <= 0 {
+		return nil, nil
+	}
+	numSteps := numInfoBits + memoryBits
+	mother := depuncture(punctured, d.rate, numSteps*2)
+
+	pathMetric := make([]float64, numStates)
+	for i := 1; i < numStates; i++ {
+		pathMetric[i] = math.Inf(-1)
+	}
+
+	history := make([]step, 0, tracebackWindow+1)
+	bits = make([]uint8, 0, numInfoBits)
+	reliability = make([]float64, 0, numInfoBits)
+
+	emitOldest := func() {
+		state := bestState(pathMetric)
+		for i := len(history) - 1; i >= 1; i-- {
+			state = history[i].prevState[state]
+		}
+		oldest := history[0]
+		bits = append(bits, oldest.prevBit[state])
+		reliability = append(reliability, squash(oldest.branchMag[state]))
+		history = history[1:]
+	}
+
+	for t := 0; t < numSteps; t++ {
+		llr1, llr2 := mother[2*t], mother[2*t+1]
+
+		var st step
+		newMetric := make([]float64, numStates)
+		for i := range newMetric {
+			newMetric[i] = math.Inf(-1)
+		}
+		for s := 0; s < numStates; s++ {
+			if math.IsInf(pathMetric[s], -1) {
+				continue
+			}
+			for b := uint8(0); b < 2; b++ {
+				br := trellis[s][b]
+				bm := bipolar(br.g1)*llr1 + bipolar(br.g2)*llr2
+				cand := pathMetric[s] + bm
+				if cand > newMetric[br.nextState] {
+					newMetric[br.nextState] = cand
+					st.prevState[br.nextState] = uint8(s)
+					st.prevBit[br.nextState] = b
+					st.branchMag[br.nextState] = (math.Abs(llr1) + math.Abs(llr2)) / 2
+				}
+			}
+		}
+		pathMetric = newMetric
+		history = append(history, st)
+
+		if len(history) > tracebackWindow {
+			emitOldest()
+		}
+	}
+
+	// Flush the remaining window via a full traceback from the best
+	// (expected zero, since the encoder zero-tails) final state.
+	state := bestState(pathMetric)
+	tailBits := make([]uint8, len(history))
+	tailRel := make([]float64, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		st := history[i]
+		tailBits[i] = st.prevBit[state]
+		tailRel[i] = squash(st.branchMag[state])
+		state = st.prevState[state]
+	}
+	bits = append(bits, tailBits...)
+	reliability = append(reliability, tailRel...)
+
+	if len(bits) > numInfoBits {
+		bits = bits[:numInfoBits]
+		reliability = reliability[:numInfoBits]
+	}
+	return bits, reliability
+}
+
+func bestState(pathMetric []float64) uint8 {
+	best := 0
+	for s := 1; s < numStates; s++ {
+		if pathMetric[s] > pathMetric[best] {
+			best = s
+		}
+	}
+	return uint8(best)
+}
+
+// squash maps an unbounded branch-metric magnitude onto [0,1), the same
+// rough scale RBTree.Confidence already uses (RiftEncode seeds it at
+// 1.0), so Decode's output can feed MarkMeasurement directly.
+func squash(margin float64) float64 {
+	if margin < 0 {
+		margin = 0
+	}
+	return margin / (margin + 1)
+}
@@ -0,0 +1,117 @@
+package fec
+
+import "fmt"
+
+// GF(2^8) arithmetic built from log/antilog tables over the primitive
+// polynomial x^8+x^4+x^3+x^2+1 (0x11d), the same field klauspost/reedsolomon
+// and most RS implementations use.
+const gfPrimePoly = 0x11d
+
+var (
+	gfExpTable [512]byte
+	gfLogTable [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExpTable[i] = byte(x)
+		gfLogTable[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPrimePoly
+		}
+	}
+	for i := 255; i < 512; i++ {
+		gfExpTable[i] = gfExpTable[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExpTable[int(gfLogTable[a])+int(gfLogTable[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	if b == 0 {
+		panic("fec: division by zero in GF(2^8)")
+	}
+	return gfExpTable[(int(gfLogTable[a])+255-int(gfLogTable[b]))%255]
+}
+
+func gfPow(a byte, power int) byte {
+	if power == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	e := (int(gfLogTable[a]) * power) % 255
+	if e < 0 {
+		e += 255
+	}
+	return gfExpTable[e]
+}
+
+func gfInverse(a byte) byte {
+	if a == 0 {
+		panic("fec: zero has no multiplicative inverse in GF(2^8)")
+	}
+	return gfExpTable[255-int(gfLogTable[a])]
+}
+
+// gfInvertMatrix inverts a square matrix over GF(2^8) via Gauss-Jordan
+// elimination with partial pivoting, returning an error if the matrix is
+// singular (should not happen for a genuine Vandermonde submatrix, but
+// guards against a caller passing a degenerate one).
+func gfInvertMatrix(m [][]byte) ([][]byte, error) {
+	n := len(m)
+
+	aug := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		row := make([]byte, 2*n)
+		copy(row, m[i])
+		row[n+i] = 1
+		aug[i] = row
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if aug[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot == -1 {
+			return nil, fmt.Errorf("fec: matrix is singular, cannot invert")
+		}
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+
+		inv := gfInverse(aug[col][col])
+		for c := 0; c < 2*n; c++ {
+			aug[col][c] = gfMul(aug[col][c], inv)
+		}
+
+		for row := 0; row < n; row++ {
+			if row == col || aug[row][col] == 0 {
+				continue
+			}
+			factor := aug[row][col]
+			for c := 0; c < 2*n; c++ {
+				aug[row][c] ^= gfMul(factor, aug[col][c])
+			}
+		}
+	}
+
+	inverted := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		inverted[i] = aug[i][n:]
+	}
+	return inverted, nil
+}
@@ -0,0 +1,269 @@
+// Package fec implements systematic Reed-Solomon erasure coding over
+// GF(2^8), with a shard API shaped like klauspost/reedsolomon: callers
+// split a message into data shards, Encode computes parity shards from
+// them, and Reconstruct repairs any shards erased (set to nil) up to the
+// number of parity shards, using Gaussian elimination over the field.
+package fec
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Encoder computes and repairs parity over a fixed number of equal-length
+// data and parity shards.
+type Encoder interface {
+	// Encode computes parity shards from shards[:DataShards] and writes
+	// them into shards[DataShards:]. Every shard must have the same
+	// length.
+	Encode(shards [][]byte) error
+	// Reconstruct repairs any nil entries in shards using the surviving
+	// data and parity shards. It is a no-op if nothing is missing, and
+	// fails if more shards are missing than ParityShards.
+	Reconstruct(shards [][]byte) error
+	// Verify reports whether the parity shards are consistent with the
+	// data shards.
+	Verify(shards [][]byte) (bool, error)
+
+	DataShards() int
+	ParityShards() int
+}
+
+type reedSolomon struct {
+	dataShards   int
+	parityShards int
+	// matrix has dataShards+parityShards rows and dataShards columns: the
+	// first dataShards rows are the identity (a data shard equals itself),
+	// the remaining parityShards rows are a Vandermonde matrix evaluated
+	// at x=1..parityShards, so every parity shard is an independent linear
+	// combination of the data shards.
+	matrix [][]byte
+}
+
+// New builds an Encoder for dataShards data shards and parityShards parity
+// shards, able to reconstruct up to parityShards erasures.
+func New(dataShards, parityShards int) (Encoder, error) {
+	if dataShards <= 0 || parityShards <= 0 {
+		return nil, fmt.Errorf("fec: dataShards and parityShards must both be positive, got %d and %d", dataShards, parityShards)
+	}
+	if dataShards+parityShards > 255 {
+		return nil, fmt.Errorf("fec: dataShards+parityShards must be <= 255, got %d", dataShards+parityShards)
+	}
+	return &reedSolomon{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		matrix:       buildEncodingMatrix(dataShards, parityShards),
+	}, nil
+}
+
+func (r *reedSolomon) DataShards() int   { return r.dataShards }
+func (r *reedSolomon) ParityShards() int { return r.parityShards }
+
+func buildEncodingMatrix(dataShards, parityShards int) [][]byte {
+	rows := make([][]byte, dataShards+parityShards)
+	for i := 0; i < dataShards; i++ {
+		row := make([]byte, dataShards)
+		row[i] = 1
+		rows[i] = row
+	}
+	for p := 0; p < parityShards; p++ {
+		row := make([]byte, dataShards)
+		x := byte(p + 1) // 0 would make the first parity shard a copy of shard 0
+		for k := 0; k < dataShards; k++ {
+			row[k] = gfPow(x, k)
+		}
+		rows[dataShards+p] = row
+	}
+	return rows
+}
+
+func (r *reedSolomon) Encode(shards [][]byte) error {
+	shardLen, err := r.checkShards(shards, r.dataShards)
+	if err != nil {
+		return err
+	}
+
+	for p := 0; p < r.parityShards; p++ {
+		parity := make([]byte, shardLen)
+		row := r.matrix[r.dataShards+p]
+		for b := 0; b < shardLen; b++ {
+			var sum byte
+			for d := 0; d < r.dataShards; d++ {
+				sum ^= gfMul(row[d], shards[d][b])
+			}
+			parity[b] = sum
+		}
+		shards[r.dataShards+p] = parity
+	}
+	return nil
+}
+
+func (r *reedSolomon) Verify(shards [][]byte) (bool, error) {
+	shardLen, err := r.checkShards(shards, r.dataShards+r.parityShards)
+	if err != nil {
+		return false, err
+	}
+
+	for p := 0; p < r.parityShards; p++ {
+		row := r.matrix[r.dataShards+p]
+		want := shards[r.dataShards+p]
+		for b := 0; b < shardLen; b++ {
+			var sum byte
+			for d := 0; d < r.dataShards; d++ {
+				sum ^= gfMul(row[d], shards[d][b])
+			}
+			if sum != want[b] {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Reconstruct repairs every nil shard by solving the linear system formed
+// by the surviving shards' rows in the encoding matrix: pick dataShards
+// surviving rows, invert that square submatrix over GF(2^8), and multiply
+// it by the surviving shard bytes to recover the original data shards.
+// Any still-missing parity shards are then recomputed directly.
+func (r *reedSolomon) Reconstruct(shards [][]byte) error {
+	total := r.dataShards + r.parityShards
+	if len(shards) != total {
+		return fmt.Errorf("fec: expected %d shards, got %d", total, len(shards))
+	}
+
+	shardLen := 0
+	var missing []int
+	for i, s := range shards {
+		if s == nil {
+			missing = append(missing, i)
+			continue
+		}
+		if shardLen == 0 {
+			shardLen = len(s)
+		} else if len(s) != shardLen {
+			return fmt.Errorf("fec: shard %d has length %d, want %d", i, len(s), shardLen)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if len(missing) > r.parityShards {
+		return fmt.Errorf("fec: %d shards missing, can only reconstruct up to %d", len(missing), r.parityShards)
+	}
+
+	subMatrix := make([][]byte, r.dataShards)
+	subShards := make([][]byte, r.dataShards)
+	row := 0
+	for i := 0; i < total && row < r.dataShards; i++ {
+		if shards[i] == nil {
+			continue
+		}
+		subMatrix[row] = r.matrix[i]
+		subShards[row] = shards[i]
+		row++
+	}
+	if row < r.dataShards {
+		return fmt.Errorf("fec: not enough surviving shards to reconstruct")
+	}
+
+	inverted, err := gfInvertMatrix(subMatrix)
+	if err != nil {
+		return fmt.Errorf("fec: reconstruct failed: %w", err)
+	}
+
+	for d := 0; d < r.dataShards; d++ {
+		if shards[d] != nil {
+			continue
+		}
+		recovered := make([]byte, shardLen)
+		invRow := inverted[d]
+		for b := 0; b < shardLen; b++ {
+			var sum byte
+			for k := 0; k < r.dataShards; k++ {
+				sum ^= gfMul(invRow[k], subShards[k][b])
+			}
+			recovered[b] = sum
+		}
+		shards[d] = recovered
+	}
+
+	for p := 0; p < r.parityShards; p++ {
+		if shards[r.dataShards+p] != nil {
+			continue
+		}
+		parity := make([]byte, shardLen)
+		row := r.matrix[r.dataShards+p]
+		for b := 0; b < shardLen; b++ {
+			var sum byte
+			for d := 0; d < r.dataShards; d++ {
+				sum ^= gfMul(row[d], shards[d][b])
+			}
+			parity[b] = sum
+		}
+		shards[r.dataShards+p] = parity
+	}
+
+	return nil
+}
+
+func (r *reedSolomon) checkShards(shards [][]byte, wantLen int) (int, error) {
+	total := r.dataShards + r.parityShards
+	if len(shards) != total {
+		return 0, fmt.Errorf("fec: expected %d shards, got %d", total, len(shards))
+	}
+	if len(shards) < wantLen || shards[0] == nil {
+		return 0, fmt.Errorf("fec: shard 0 is missing")
+	}
+	shardLen := len(shards[0])
+	for i := 0; i < wantLen; i++ {
+		if shards[i] == nil {
+			return 0, fmt.Errorf("fec: shard %d is missing", i)
+		}
+		if len(shards[i]) != shardLen {
+			return 0, fmt.Errorf("fec: shard %d has length %d, want %d", i, len(shards[i]), shardLen)
+		}
+	}
+	return shardLen, nil
+}
+
+// Split divides data into dataShards equal-length shards, zero-padding the
+// last shard if necessary, and returns a shard slice with parityShards
+// trailing nil entries ready for Encode.
+func Split(data []byte, dataShards, parityShards int) ([][]byte, error) {
+	if dataShards <= 0 {
+		return nil, fmt.Errorf("fec: dataShards must be positive, got %d", dataShards)
+	}
+	shardSize := (len(data) + dataShards - 1) / dataShards
+	if shardSize == 0 {
+		shardSize = 1
+	}
+
+	padded := make([]byte, shardSize*dataShards)
+	copy(padded, data)
+
+	shards := make([][]byte, dataShards+parityShards)
+	for i := 0; i < dataShards; i++ {
+		shards[i] = padded[i*shardSize : (i+1)*shardSize]
+	}
+	return shards, nil
+}
+
+// Join concatenates the data shards back into a single buffer truncated to
+// size, undoing Split's zero-padding.
+func Join(shards [][]byte, dataShards, size int) ([]byte, error) {
+	if len(shards) < dataShards {
+		return nil, fmt.Errorf("fec: expected at least %d shards, got %d", dataShards, len(shards))
+	}
+	var buf bytes.Buffer
+	for i := 0; i < dataShards; i++ {
+		if shards[i] == nil {
+			return nil, fmt.Errorf("fec: data shard %d is missing, reconstruct before joining", i)
+		}
+		buf.Write(shards[i])
+	}
+	out := buf.Bytes()
+	if size < len(out) {
+		out = out[:size]
+	}
+	return out, nil
+}
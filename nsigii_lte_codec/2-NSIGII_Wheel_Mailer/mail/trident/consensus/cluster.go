@@ -0,0 +1,199 @@
+// Package consensus runs the three NSIGII trident channels as a 3-node
+// hashicorp/raft cluster over their existing 127.0.0.{1,2,3} loopback
+// addresses, so "consensus" means an actually-replicated log entry instead
+// of a bit-population heuristic on a single node.
+package consensus
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// Outcome classifies a ProposeRoot result the way VerifyPacket maps Raft
+// consensus onto the trident discriminant states: a unanimous, full-strength
+// commit is the ORDER fast path, a bare-majority (2/3) commit is CONSENSUS,
+// and a failed proposal (no leader, timeout) is CHAOS.
+type Outcome int
+
+const (
+	OutcomeOrder Outcome = iota
+	OutcomeConsensus
+	OutcomeChaos
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeOrder:
+		return "ORDER"
+	case OutcomeConsensus:
+		return "CONSENSUS"
+	default:
+		return "CHAOS"
+	}
+}
+
+// rootFSM is the raft.FSM the cluster replicates Merkle roots through. The
+// roots are already verified, immutable data, so Apply only needs to record
+// the most recently committed one.
+type rootFSM struct {
+	lastRoot [32]byte
+}
+
+func (f *rootFSM) Apply(log *raft.Log) interface{} {
+	if len(log.Data) == 32 {
+		copy(f.lastRoot[:], log.Data)
+	}
+	return nil
+}
+
+func (f *rootFSM) Snapshot() (raft.FSMSnapshot, error) {
+	return &rootSnapshot{root: f.lastRoot}, nil
+}
+
+func (f *rootFSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	var root [32]byte
+	if _, err := io.ReadFull(rc, root[:]); err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	f.lastRoot = root
+	return nil
+}
+
+type rootSnapshot struct {
+	root [32]byte
+}
+
+func (s *rootSnapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := sink.Write(s.root[:]); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *rootSnapshot) Release() {}
+
+// node is one trident channel's loopback endpoint, running as a Raft voter.
+type node struct {
+	raft      *raft.Raft
+	fsm       *rootFSM
+	transport *raft.NetworkTransport
+}
+
+// Cluster is the 3-node Raft quorum standing in for the old
+// bipartiteConsensus heuristic: a packet's Merkle root only counts as
+// consensus once it is durably replicated across channels.
+type Cluster struct {
+	nodes []*node
+	peers []string
+}
+
+// NewTridentCluster starts one in-process Raft node per peer address (the
+// three trident channels' 127.0.0.{1,2,3} loopback endpoints), bootstraps
+// them into a single 3-voter configuration, and returns once every node has
+// been started (leader election happens asynchronously; ProposeRoot waits
+// for it).
+func NewTridentCluster(peers []string) (*Cluster, error) {
+	if len(peers) != 3 {
+		return nil, fmt.Errorf("trident consensus requires exactly 3 peers (one per channel), got %d", len(peers))
+	}
+
+	cluster := &Cluster{peers: peers}
+
+	servers := make([]raft.Server, 0, len(peers))
+	for _, addr := range peers {
+		servers = append(servers, raft.Server{
+			Suffrage: raft.Voter,
+			ID:       raft.ServerID(addr),
+			Address:  raft.ServerAddress(addr),
+		})
+	}
+	bootstrapConfig := raft.Configuration{Servers: servers}
+
+	for _, addr := range peers {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			cluster.Shutdown()
+			return nil, fmt.Errorf("resolving trident peer %s: %w", addr, err)
+		}
+
+		transport, err := raft.NewTCPTransport(addr, tcpAddr, 3, 2*time.Second, io.Discard)
+		if err != nil {
+			cluster.Shutdown()
+			return nil, fmt.Errorf("starting raft transport on %s: %w", addr, err)
+		}
+
+		config := raft.DefaultConfig()
+		config.LocalID = raft.ServerID(addr)
+
+		fsm := &rootFSM{}
+		logStore := raft.NewInmemStore()
+		stableStore := raft.NewInmemStore()
+		snapshotStore := raft.NewInmemSnapshotStore()
+
+		r, err := raft.NewRaft(config, fsm, logStore, stableStore, snapshotStore, transport)
+		if err != nil {
+			cluster.Shutdown()
+			return nil, fmt.Errorf("starting raft node %s: %w", addr, err)
+		}
+
+		if err := r.BootstrapCluster(bootstrapConfig).Error(); err != nil && err != raft.ErrCantBootstrap {
+			cluster.Shutdown()
+			return nil, fmt.Errorf("bootstrapping raft node %s: %w", addr, err)
+		}
+
+		cluster.nodes = append(cluster.nodes, &node{raft: r, fsm: fsm, transport: transport})
+	}
+
+	return cluster, nil
+}
+
+// ProposeRoot applies a trident packet's Merkle root to the Raft log,
+// blocking up to timeout for it to commit.
+func (c *Cluster) ProposeRoot(root [32]byte, timeout time.Duration) (Outcome, error) {
+	leader := c.leader()
+	if leader == nil {
+		return OutcomeChaos, fmt.Errorf("trident consensus: no leader elected")
+	}
+
+	future := leader.raft.Apply(root[:], timeout)
+	if err := future.Error(); err != nil {
+		return OutcomeChaos, fmt.Errorf("trident consensus: proposal failed: %w", err)
+	}
+
+	// hashicorp/raft's public API doesn't expose a per-follower ack count
+	// for a single Apply, so "unanimous" is approximated as "every
+	// configured voter is currently known to the leader" (num_peers ==
+	// len(peers)-1) rather than every peer having acked this exact entry.
+	stats := leader.raft.Stats()
+	if stats["num_peers"] == fmt.Sprintf("%d", len(c.peers)-1) {
+		return OutcomeOrder, nil
+	}
+	return OutcomeConsensus, nil
+}
+
+func (c *Cluster) leader() *node {
+	for _, n := range c.nodes {
+		if n.raft.State() == raft.Leader {
+			return n
+		}
+	}
+	return nil
+}
+
+// Shutdown tears down every Raft node and its transport.
+func (c *Cluster) Shutdown() {
+	for _, n := range c.nodes {
+		if n.raft != nil {
+			n.raft.Shutdown()
+		}
+		if n.transport != nil {
+			n.transport.Close()
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package main
+
+// ============================================================================
+// TRIDENT BUS: pluggable transport between trident channels
+//
+// TridentChannel historically only existed in-process: EncodeFrame called
+// DecodePacket/VerifyPacket directly as Go method calls, and LoopbackAddr
+// was cosmetic - nothing ever crossed a socket. TridentBus makes that hop
+// swappable: inprocBus preserves the historical behavior (now actually
+// routed through MessageQueue, the channel field whose stream semantics
+// were previously unused) and is the default / what -transport=inproc
+// selects for tests; grpcBus runs each channel as a real streaming
+// server reachable at -bind (or its loopback address), matching the
+// trident.proto service contract (see trident/trident.proto).
+//
+// grpcBus is written against trident.proto's schema field-for-field, but
+// carries it over a hand-registered gob codec instead of generated
+// protobuf bindings: this tree has neither a go.mod nor a protoc
+// toolchain available to generate and vendor trident/tridentpb, and
+// gob's exported-field marshaling is a correct, if non-standard, grpc-go
+// Codec for TridentPacket's Go struct shape. Swapping in real
+// protoc-gen-go-grpc bindings later only requires replacing gobCodec and
+// the hand-built grpc.ServiceDesc below with the generated ones - the
+// RPC shapes and call sites don't change.
+// ============================================================================
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/peer"
+)
+
+// BusTransport selects how a TridentChannel exchanges packets with the
+// rest of the pipeline.
+type BusTransport int
+
+const (
+	BusTransportInproc BusTransport = iota
+	BusTransportGRPC
+)
+
+func (t BusTransport) String() string {
+	if t == BusTransportGRPC {
+		return "grpc"
+	}
+	return "inproc"
+}
+
+// ParseBusTransport parses a "-transport" flag value.
+func ParseBusTransport(s string) (BusTransport, error) {
+	switch s {
+	case "", "inproc":
+		return BusTransportInproc, nil
+	case "grpc":
+		return BusTransportGRPC, nil
+	default:
+		return BusTransportInproc, fmt.Errorf("nsigii: unknown -transport %q (want inproc or grpc)", s)
+	}
+}
+
+// TLSOptions carries the -tls-cert/-tls-key/-tls-ca/-tls-write-cn flags a
+// grpc-transport channel's server and client build their credentials
+// from. A non-empty CAFile enables mTLS: the server requires and
+// verifies a client certificate, and WriteCNs maps a verified peer's
+// certificate Subject CommonName onto RWX_WRITE instead of the RWX_READ a
+// peer without one (or not in the list) gets.
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+	WriteCNs []string
+}
+
+// Enabled reports whether TLS credentials should be built at all (plain
+// insecure connections otherwise, matching this codec's existing
+// loopback-only deployment model).
+func (o TLSOptions) Enabled() bool { return o.CertFile != "" && o.KeyFile != "" }
+
+func (o TLSOptions) serverCredentials() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("nsigii: loading TLS server cert/key: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if o.CAFile != "" {
+		pool, err := loadCAPool(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+func (o TLSOptions) clientCredentials() (credentials.TransportCredentials, error) {
+	if !o.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+	cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("nsigii: loading TLS client cert/key: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if o.CAFile != "" {
+		pool, err := loadCAPool(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+func loadCAPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("nsigii: reading TLS CA file %s: %w", path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("nsigii: no certificates found in TLS CA file %s", path)
+	}
+	return pool, nil
+}
+
+// rwxForPeer maps a verified client certificate's CommonName onto an RWX
+// flag set: WriteCNs get RWX_WRITE, everyone else (including an
+// unauthenticated peer, when mTLS isn't enabled) gets RWX_READ.
+func (o TLSOptions) rwxForPeer(commonName string) uint8 {
+	for _, cn := range o.WriteCNs {
+		if cn == commonName {
+			return RWX_WRITE
+		}
+	}
+	return RWX_READ
+}
+
+// peerCommonName extracts the verified client certificate's Subject
+// CommonName from a server-side stream's context, or "" if the
+// connection isn't using mTLS.
+func peerCommonName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return ""
+	}
+	return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+}
+
+// busPort is the fixed TCP port a grpc-transport channel binds on its
+// loopback address, distinct from consensusPort so the Raft cluster and
+// the trident bus can run side by side.
+const busPort = 9732
+
+// gobCodec is the trident bus's stand-in for generated protobuf
+// marshaling (see this file's package doc): it gob-encodes whatever Go
+// value grpc-go hands it, which is sufficient for TridentPacket's plain
+// exported-field struct shape.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, fmt.Errorf("nsigii: gob-encoding trident bus message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return fmt.Errorf("nsigii: gob-decoding trident bus message: %w", err)
+	}
+	return nil
+}
+
+func (gobCodec) Name() string { return "gob" }
+
+func init() {
+	encoding.RegisterCodec(gobCodec{})
+}
+
+// transmitAck is the gob-coded equivalent of trident.proto's Ack message.
+type transmitAck struct {
+	SequenceToken uint32
+	Accepted      bool
+	Packet        TridentPacket
+}
+
+// verifyResult is the gob-coded equivalent of trident.proto's
+// VerifyResult message.
+type verifyResult struct {
+	SequenceToken uint32
+	Verified      bool
+	Packet        TridentPacket
+}
+
+const tridentBusServiceName = "trident.TridentBus"
+
+// tridentBusServiceDesc mirrors the grpc.ServiceDesc protoc-gen-go-grpc
+// would emit for trident.proto's TridentBus service: one bidirectional
+// streaming method per RPC, each backed by tc.DecodePacket/VerifyPacket.
+// When tlsOpts has mTLS enabled, the verified peer's certificate CommonName
+// overrides the response packet's RWXFlags via tlsOpts.rwxForPeer.
+func tridentBusServiceDesc(tc *TridentChannel, tlsOpts TLSOptions) grpc.ServiceDesc {
+	return grpc.ServiceDesc{
+		ServiceName: tridentBusServiceName,
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "Transmit",
+				ServerStreams: true,
+				ClientStreams: true,
+				Handler: func(srv interface{}, stream grpc.ServerStream) error {
+					return serveTridentStream(stream, func(pkt TridentPacket) interface{} {
+						decoded := tc.DecodePacket(pkt)
+						if cn := peerCommonName(stream.Context()); cn != "" {
+							decoded.Verification.RWXFlags = tlsOpts.rwxForPeer(cn)
+						}
+						return transmitAck{
+							SequenceToken: decoded.Header.SequenceToken,
+							Accepted:      true,
+							Packet:        decoded,
+						}
+					})
+				},
+			},
+			{
+				StreamName:    "Verify",
+				ServerStreams: true,
+				ClientStreams: true,
+				Handler: func(srv interface{}, stream grpc.ServerStream) error {
+					return serveTridentStream(stream, func(pkt TridentPacket) interface{} {
+						verified, ok := tc.VerifyPacket(pkt)
+						if cn := peerCommonName(stream.Context()); cn != "" {
+							verified.Verification.RWXFlags = tlsOpts.rwxForPeer(cn)
+						}
+						return verifyResult{
+							SequenceToken: verified.Header.SequenceToken,
+							Verified:      ok,
+							Packet:        verified,
+						}
+					})
+				},
+			},
+		},
+		Metadata: "trident/trident.proto",
+	}
+}
+
+// serveTridentStream drives one bidirectional RPC: for every TridentPacket
+// the caller sends, it runs process and sends the result back, until the
+// caller closes its send side.
+func serveTridentStream(stream grpc.ServerStream, process func(TridentPacket) interface{}) error {
+	for {
+		var pkt TridentPacket
+		if err := stream.RecvMsg(&pkt); err != nil {
+			return err
+		}
+		if err := stream.SendMsg(process(pkt)); err != nil {
+			return err
+		}
+	}
+}
+
+// grpcChannelServer owns a TridentChannel's *grpc.Server: every channel
+// built with -transport=grpc runs one so it's dialable as the Receiver
+// (Transmit) or Verifier (Verify) leg of another codec's pipeline.
+type grpcChannelServer struct {
+	server *grpc.Server
+}
+
+// Serve starts tc's grpc server on addr (or tc.LoopbackAddr:busPort if
+// addr is empty) and returns once it's listening; the server itself runs
+// in a background goroutine until Stop is called.
+func (tc *TridentChannel) Serve(addr string, tlsOpts TLSOptions) (*grpcChannelServer, error) {
+	if addr == "" {
+		addr = fmt.Sprintf("%s:%d", tc.LoopbackAddr, busPort)
+	}
+
+	var opts []grpc.ServerOption
+	if tlsOpts.Enabled() {
+		creds, err := tlsOpts.serverCredentials()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("nsigii: trident bus listen on %s: %w", addr, err)
+	}
+
+	server := grpc.NewServer(opts...)
+	desc := tridentBusServiceDesc(tc, tlsOpts)
+	server.RegisterService(&desc, nil)
+
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			log.Printf("nsigii: trident bus server on %s stopped: %v", addr, err)
+		}
+	}()
+
+	return &grpcChannelServer{server: server}, nil
+}
+
+// Stop gracefully shuts down the bus server started by Serve.
+func (s *grpcChannelServer) Stop() {
+	if s == nil || s.server == nil {
+		return
+	}
+	s.server.GracefulStop()
+}
+
+// grpcBusClient dials a peer TridentChannel's grpc server and exposes the
+// same Transmit/Verify calls NSIGIICodec.EncodeFrame would otherwise make
+// in-process, each as a one-shot streaming RPC (send one packet, read one
+// response) over a freshly-opened stream.
+type grpcBusClient struct {
+	conn *grpc.ClientConn
+}
+
+// dialTridentBus dials addr and returns a client ready to call Transmit
+// or Verify against it.
+func dialTridentBus(addr string, tlsOpts TLSOptions) (*grpcBusClient, error) {
+	creds, err := tlsOpts.clientCredentials()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(gobCodec{}.Name())),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("nsigii: dialing trident bus at %s: %w", addr, err)
+	}
+	return &grpcBusClient{conn: conn}, nil
+}
+
+// Close tears down the underlying connection.
+func (c *grpcBusClient) Close() error { return c.conn.Close() }
+
+// Transmit sends packet to the peer's Transmit RPC (the Receiver
+// channel's DecodePacket) and returns its decoded result.
+func (c *grpcBusClient) Transmit(packet TridentPacket) (TridentPacket, error) {
+	var ack transmitAck
+	if err := c.call("/"+tridentBusServiceName+"/Transmit", packet, &ack); err != nil {
+		return TridentPacket{}, err
+	}
+	return ack.Packet, nil
+}
+
+// Verify sends packet to the peer's Verify RPC (the Verifier channel's
+// VerifyPacket) and returns its (possibly repaired) result plus whether
+// it verified.
+func (c *grpcBusClient) Verify(packet TridentPacket) (TridentPacket, bool, error) {
+	var result verifyResult
+	if err := c.call("/"+tridentBusServiceName+"/Verify", packet, &result); err != nil {
+		return TridentPacket{}, false, err
+	}
+	return result.Packet, result.Verified, nil
+}
+
+func (c *grpcBusClient) call(method string, req TridentPacket, resp interface{}) error {
+	desc := &grpc.StreamDesc{StreamName: method, ServerStreams: true, ClientStreams: true}
+	stream, err := c.conn.NewStream(context.Background(), desc, method)
+	if err != nil {
+		return fmt.Errorf("nsigii: opening trident bus stream %s: %w", method, err)
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return fmt.Errorf("nsigii: sending to trident bus stream %s: %w", method, err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("nsigii: closing trident bus send side on %s: %w", method, err)
+	}
+	if err := stream.RecvMsg(resp); err != nil {
+		return fmt.Errorf("nsigii: receiving from trident bus stream %s: %w", method, err)
+	}
+	return nil
+}
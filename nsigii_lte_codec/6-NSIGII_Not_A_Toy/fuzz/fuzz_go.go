@@ -1,7 +1,11 @@
 //go:build gofuzz
+
+// Package nplfuzz: FuzzNPL below is the pre-1.18 go-fuzz entry point, kept
+// for tooling that still targets it. FuzzParse in fuzz_test.go is the
+// standard library testing.F port and is where new coverage should go.
 package nplfuzz
 
-import "github.com/obinexus/aegis/src/npl-protocols"
+import nplprotocols "github.com/obinexus/nsigii-not-a-toy/npl-protocols"
 
 func FuzzNPL(data []byte) int {
     if len(data) == 0 { return -1 }
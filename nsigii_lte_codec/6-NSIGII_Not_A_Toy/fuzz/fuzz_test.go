@@ -0,0 +1,84 @@
+package nplfuzz
+
+// FuzzParse ports FuzzNPL (fuzz_go.go) from the deprecated pre-1.18
+// //go:build gofuzz convention to the standard library's testing.F fuzzer.
+//
+// The real github.com/obinexus/aegis/src/npl-protocols isn't vendored into
+// this snapshot, so FuzzParse instead builds against
+// ../npl-protocols, a minimal local package implementing just the
+// TYPE|field|field... frame grammar seedFrames and testdata/fuzz/FuzzParse
+// already assume, behind the identical Parse/Marshal/ParseError interface.
+// It drops in unmodified once the real package lands here; until then this
+// is what `go test ./...` in this directory actually builds and fuzzes
+// against.
+//
+// The request also asked for a second fuzz function driving a Client's
+// protocolHandler through its state transitions with fuzzed payloads. That
+// state machine now exists (internal.ProtocolHandler in the go-polycall
+// binding module), but it lives under an internal/ directory rooted at a
+// different module entirely - Go's internal-import visibility rule (scoped
+// by directory tree, not by module) forbids importing it from here
+// regardless of any replace directive, so that target stays out of scope
+// until protocolHandler is exported or lands in this tree directly.
+
+import (
+	"reflect"
+	"testing"
+
+	nplprotocols "github.com/obinexus/nsigii-not-a-toy/npl-protocols"
+)
+
+// seedFrames are known-valid NPL frames, one per protocol phase, so the
+// corpus exercises handshake/auth/execute/shutdown from the first run.
+var seedFrames = [][]byte{
+	[]byte("HELLO|handshake|v1"),
+	[]byte("AUTH|token=deadbeef"),
+	[]byte("EXEC|feature=status"),
+	[]byte("BYE|shutdown"),
+}
+
+// knownBadFrames are placeholders for the known-bad frames the request
+// asks to be captured from prior crashes; FuzzNPL kept no corpus of its
+// own, so these are the obvious malformed-input cases until real crashers
+// are recorded under testdata/fuzz/FuzzParse/.
+var knownBadFrames = [][]byte{
+	{},
+	{0x00},
+}
+
+func FuzzParse(f *testing.F) {
+	for _, frame := range seedFrames {
+		f.Add(frame)
+	}
+	for _, frame := range knownBadFrames {
+		f.Add(frame)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("Parse panicked on input %x: %v", data, r)
+			}
+		}()
+
+		msg, err := nplprotocols.Parse(data)
+		if err != nil {
+			if _, ok := err.(*nplprotocols.ParseError); !ok {
+				t.Fatalf("Parse returned untyped error %T for input %x: %v", err, data, err)
+			}
+			return
+		}
+
+		encoded, err := nplprotocols.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal failed for a value Parse just accepted (%x): %v", data, err)
+		}
+		roundTripped, err := nplprotocols.Parse(encoded)
+		if err != nil {
+			t.Fatalf("round-tripped frame failed to parse: %v", err)
+		}
+		if !reflect.DeepEqual(msg, roundTripped) {
+			t.Fatalf("round trip mismatch: %+v != %+v", msg, roundTripped)
+		}
+	})
+}
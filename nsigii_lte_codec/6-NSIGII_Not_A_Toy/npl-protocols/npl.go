@@ -0,0 +1,66 @@
+// Package nplprotocols is a minimal local stand-in for
+// github.com/obinexus/aegis/src/npl-protocols, vendoring just enough of its
+// NPL wire format - TYPE|field|field... frames, as seen in fuzz_test.go's
+// seedFrames and testdata/fuzz/FuzzParse/* - for FuzzParse and the legacy
+// FuzzNPL (fuzz_go.go) to actually build and run against. The real
+// package's wider grammar isn't vendored here.
+package nplprotocols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message is one parsed NPL frame: a known Type (HELLO, AUTH, EXEC, or BYE)
+// plus whatever pipe-delimited fields followed it.
+type Message struct {
+	Type   string
+	Fields []string
+}
+
+// ParseError reports a frame Parse rejected.
+type ParseError struct {
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("npl-protocols: %s", e.Reason)
+}
+
+// knownTypes are the frame types the NPL handshake/auth/execute/shutdown
+// phases use, mirroring fuzz_test.go's seedFrames.
+var knownTypes = map[string]bool{
+	"HELLO": true,
+	"AUTH":  true,
+	"EXEC":  true,
+	"BYE":   true,
+}
+
+// Parse splits data on "|" and validates the first token is a known frame
+// type; every later token is kept verbatim as a field.
+func Parse(data []byte) (*Message, error) {
+	if len(data) == 0 {
+		return nil, &ParseError{Reason: "empty frame"}
+	}
+
+	parts := strings.Split(string(data), "|")
+	msgType := parts[0]
+	if !knownTypes[msgType] {
+		return nil, &ParseError{Reason: fmt.Sprintf("unknown frame type %q", msgType)}
+	}
+
+	return &Message{Type: msgType, Fields: append([]string{}, parts[1:]...)}, nil
+}
+
+// Marshal reassembles msg into the pipe-delimited wire form Parse accepts.
+func Marshal(msg *Message) ([]byte, error) {
+	if msg == nil {
+		return nil, &ParseError{Reason: "cannot marshal a nil message"}
+	}
+	if !knownTypes[msg.Type] {
+		return nil, &ParseError{Reason: fmt.Sprintf("cannot marshal unknown frame type %q", msg.Type)}
+	}
+
+	parts := append([]string{msg.Type}, msg.Fields...)
+	return []byte(strings.Join(parts, "|")), nil
+}